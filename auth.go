@@ -0,0 +1,197 @@
+package universum
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Credentials authenticate a connection either with a username and password
+// or with a token. Empty credentials skip authentication.
+type Credentials struct {
+	Username string
+	Password string
+	Token    string
+}
+
+// isEmpty reports whether the credentials carry nothing to authenticate with
+func (c Credentials) isEmpty() bool {
+	return c.Username == "" && c.Password == "" && c.Token == ""
+}
+
+// CredentialsProvider supplies the current credentials, e.g. from a secrets
+// manager which rotates them. It is called for every new connection and
+// periodically as configured by Options.CredentialsRefreshInterval.
+type CredentialsProvider func(ctx context.Context) (Credentials, error)
+
+// authenticator authenticates the connections of a pool. It caches the last
+// credentials returned by the provider together with a generation counter
+// which is bumped whenever they change, so that connections authenticated
+// with an older generation are re-authenticated before their next use.
+type authenticator struct {
+	opts     *Options
+	provider CredentialsProvider
+
+	mu         sync.Mutex
+	current    Credentials
+	generation int64
+
+	stopOnce    sync.Once
+	stopRefresh chan struct{}
+	refreshDone chan struct{}
+}
+
+// newAuthenticator returns the authenticator for opts, or nil if neither
+// credentials nor a CredentialsProvider are configured.
+func newAuthenticator(opts *Options) *authenticator {
+	static := Credentials{Username: opts.Username, Password: opts.Password, Token: opts.Token}
+
+	provider := opts.CredentialsProvider
+	if provider == nil {
+		if static.isEmpty() {
+			return nil
+		}
+
+		provider = func(context.Context) (Credentials, error) {
+			return static, nil
+		}
+	}
+
+	auth := &authenticator{opts: opts, provider: provider}
+
+	if opts.CredentialsProvider != nil && opts.CredentialsRefreshInterval > 0 {
+		auth.stopRefresh = make(chan struct{})
+		auth.refreshDone = make(chan struct{})
+		go auth.refreshPeriodically()
+	}
+
+	return auth
+}
+
+// refresh fetches the credentials from the provider, bumping the generation
+// if they differ from the cached ones.
+func (a *authenticator) refresh(ctx context.Context) (Credentials, int64, error) {
+	creds, err := a.provider(ctx)
+	if err != nil {
+		return Credentials{}, 0, fmt.Errorf("failed to obtain credentials [%v]: %w", err, ErrCredentialsUnavailable)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.generation == 0 || creds != a.current {
+		a.current = creds
+		a.generation++
+	}
+
+	return a.current, a.generation, nil
+}
+
+// cached returns the last fetched credentials and their generation
+func (a *authenticator) cached() (Credentials, int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.current, a.generation
+}
+
+// authenticateNew authenticates a freshly dialed connection with the current
+// credentials of the provider.
+func (a *authenticator) authenticateNew(ctx context.Context, conn connInterface) error {
+	if a == nil {
+		return nil
+	}
+
+	creds, generation, err := a.refresh(ctx)
+	if err != nil {
+		return err
+	}
+
+	return a.authenticate(ctx, conn, creds, generation)
+}
+
+// ensureCurrent re-authenticates a pooled connection if the credentials changed
+// since it was authenticated.
+func (a *authenticator) ensureCurrent(ctx context.Context, conn connInterface) error {
+	if a == nil {
+		return nil
+	}
+
+	creds, generation := a.cached()
+	if conn.getAuthGeneration() == generation {
+		return nil
+	}
+
+	return a.authenticate(ctx, conn, creds, generation)
+}
+
+// reauthenticate fetches fresh credentials and authenticates the connection
+// again, used when the server reports that its authentication has expired.
+func (a *authenticator) reauthenticate(ctx context.Context, conn connInterface) error {
+	creds, generation, err := a.refresh(ctx)
+	if err != nil {
+		return err
+	}
+
+	return a.authenticate(ctx, conn, creds, generation)
+}
+
+// authenticate sends AUTH with the credentials on the connection. Rejections
+// by the server are reported as ErrAuthFailed, while socket failures are
+// returned as they are so that the connection is discarded.
+func (a *authenticator) authenticate(ctx context.Context, conn connInterface, creds Credentials, generation int64) error {
+	if !creds.isEmpty() {
+		args := []interface{}{creds.Username, creds.Password}
+		if creds.Token != "" {
+			args = []interface{}{creds.Token}
+		}
+
+		result, err := execOnConn(ctx, conn, a.opts, newCommandTrace(), commandAuth, args...)
+		if err != nil {
+			if isConnBroken(err) {
+				return err
+			}
+			return fmt.Errorf("authentication failed [%v]: %w", err, ErrAuthFailed)
+		}
+
+		if result.code != RespAuthSuccess {
+			return fmt.Errorf("authentication rejected by server with code %d [%s]: %w",
+				result.code, result.message, ErrAuthFailed)
+		}
+	}
+
+	conn.setAuthGeneration(generation)
+	return nil
+}
+
+// refreshPeriodically polls the provider until the authenticator is stopped,
+// so that rotated credentials are picked up by pooled connections.
+func (a *authenticator) refreshPeriodically() {
+	defer close(a.refreshDone)
+
+	ticker := time.NewTicker(a.opts.CredentialsRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.stopRefresh:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), a.opts.CredentialsRefreshInterval)
+			_, _, _ = a.refresh(ctx)
+			cancel()
+		}
+	}
+}
+
+// stop terminates the periodic refresh, if any
+func (a *authenticator) stop() {
+	if a == nil || a.stopRefresh == nil {
+		return
+	}
+
+	a.stopOnce.Do(func() {
+		close(a.stopRefresh)
+		<-a.refreshDone
+	})
+}
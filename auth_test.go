@@ -0,0 +1,179 @@
+package universum
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// authServer is a fake server which requires every connection to authenticate
+// with one of the accepted secrets before running commands
+type authServer struct {
+	*fakeServer
+
+	mu       sync.Mutex
+	accepted map[string]bool
+	auths    int64
+}
+
+func newAuthServer(t *testing.T, secrets ...string) *authServer {
+	server := &authServer{accepted: make(map[string]bool)}
+	server.accept(secrets...)
+
+	server.fakeServer = newFakeServerPerConn(t, func() fakeHandler {
+		var authedWith string
+
+		return func(cmd []interface{}) interface{} {
+			if cmd[0] == commandAuth {
+				atomic.AddInt64(&server.auths, 1)
+
+				secret := cmd[len(cmd)-1].(string)
+				if !server.isAccepted(secret) {
+					return fakeReply(nil, RespAuthFailed, "invalid credentials")
+				}
+
+				authedWith = secret
+				return fakeReply(nil, RespAuthSuccess, "OK")
+			}
+
+			if authedWith == "" || !server.isAccepted(authedWith) {
+				return fakeReply(nil, RespAuthRequired, "authentication required")
+			}
+
+			return fakeReply(nil, RespRecordNotFound, "")
+		}
+	})
+
+	return server
+}
+
+// accept replaces the set of accepted secrets, expiring sessions using other ones
+func (s *authServer) accept(secrets ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.accepted = make(map[string]bool)
+	for _, secret := range secrets {
+		s.accepted[secret] = true
+	}
+}
+
+func (s *authServer) isAccepted(secret string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accepted[secret]
+}
+
+func newAuthClient(t *testing.T, server *authServer, configure func(opts *Options)) *Client {
+	return newClientFor(t, server.fakeServer, func(opts *Options) {
+		opts.MaxRetries = 1
+		configure(opts)
+	})
+}
+
+func TestAuth_Password(t *testing.T) {
+	server := newAuthServer(t, "secret")
+	client := newAuthClient(t, server, func(opts *Options) {
+		opts.Username = "app"
+		opts.Password = "secret"
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Get(context.Background(), "key"); err != nil {
+			t.Fatalf("Expected authenticated command to succeed, got %v", err)
+		}
+	}
+
+	if auths := atomic.LoadInt64(&server.auths); auths != 1 {
+		t.Fatalf("Expected a single AUTH for the pooled connection, got %d", auths)
+	}
+}
+
+func TestAuth_Token(t *testing.T) {
+	server := newAuthServer(t, "token-1")
+	client := newAuthClient(t, server, func(opts *Options) {
+		opts.Token = "token-1"
+	})
+
+	if _, err := client.Get(context.Background(), "key"); err != nil {
+		t.Fatalf("Expected token authentication to succeed, got %v", err)
+	}
+}
+
+func TestAuth_Failure(t *testing.T) {
+	server := newAuthServer(t, "secret")
+	client := newAuthClient(t, server, func(opts *Options) {
+		opts.Username = "app"
+		opts.Password = "wrong"
+	})
+
+	_, err := client.Get(context.Background(), "key")
+	if !errors.Is(err, ErrAuthFailed) {
+		t.Fatalf("Expected ErrAuthFailed, got %v", err)
+	}
+	if errors.Is(err, ErrConnectionDialFailed) {
+		t.Fatalf("Expected auth failure to be distinct from dial failure, got %v", err)
+	}
+	if client.pool.Len() != 0 {
+		t.Fatalf("Expected unauthenticated connection to stay out of the pool, got %d", client.pool.Len())
+	}
+}
+
+func TestAuth_ProviderError(t *testing.T) {
+	server := newAuthServer(t, "secret")
+	client := newAuthClient(t, server, func(opts *Options) {
+		opts.CredentialsProvider = func(context.Context) (Credentials, error) {
+			return Credentials{}, errors.New("vault sealed")
+		}
+	})
+
+	if _, err := client.Get(context.Background(), "key"); !errors.Is(err, ErrCredentialsUnavailable) {
+		t.Fatalf("Expected ErrCredentialsUnavailable, got %v", err)
+	}
+}
+
+func TestAuth_RotatedCredentials(t *testing.T) {
+	server := newAuthServer(t, "v1")
+
+	var current atomic.Value
+	current.Store("v1")
+
+	client := newAuthClient(t, server, func(opts *Options) {
+		opts.CredentialsRefreshInterval = -1
+		opts.CredentialsProvider = func(context.Context) (Credentials, error) {
+			return Credentials{Username: "app", Password: current.Load().(string)}, nil
+		}
+	})
+
+	ctx := context.Background()
+	if _, err := client.Get(ctx, "key"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// rotation while both secrets are valid: the idle connection re-authenticates on checkout
+	server.accept("v1", "v2")
+	current.Store("v2")
+	if _, _, err := client.pool.auth.refresh(ctx); err != nil {
+		t.Fatalf("Expected no error refreshing credentials, got %v", err)
+	}
+
+	if _, err := client.Get(ctx, "key"); err != nil {
+		t.Fatalf("Expected no error after rotation, got %v", err)
+	}
+	if auths := atomic.LoadInt64(&server.auths); auths != 2 {
+		t.Fatalf("Expected the pooled connection to re-authenticate, got %d AUTH commands", auths)
+	}
+
+	// the server expires the session: the command is retried after re-authenticating
+	server.accept("v3")
+	current.Store("v3")
+
+	if _, err := client.Get(ctx, "key"); err != nil {
+		t.Fatalf("Expected the command to succeed after re-authentication, got %v", err)
+	}
+	if client.pool.Len() != 1 {
+		t.Fatalf("Expected the connection to be kept, got %d live", client.pool.Len())
+	}
+}
@@ -0,0 +1,107 @@
+package universum
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// aimdDecreaseFactor is the multiplicative decrease applied to the allowed
+// concurrency every time the server reports that it is busy.
+const aimdDecreaseFactor = 0.5
+
+// aimdLimiter adapts the number of commands allowed in flight to the load of
+// the server: every busy response halves the limit while every successful
+// response grows it additively, by one per limit's worth of successes.
+type aimdLimiter struct {
+	mu    sync.Mutex
+	limit float64
+	max   float64
+	slots *concurrencyLimiter
+}
+
+func newAIMDLimiter(max int64) *aimdLimiter {
+	return &aimdLimiter{
+		limit: float64(max),
+		max:   float64(max),
+		slots: newConcurrencyLimiter(max),
+	}
+}
+
+func (al *aimdLimiter) acquire(ctx context.Context) error {
+	return al.slots.acquire(ctx, false)
+}
+
+func (al *aimdLimiter) release() {
+	al.slots.release()
+}
+
+// onBusy multiplicatively decreases the allowed concurrency.
+func (al *aimdLimiter) onBusy() {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	al.limit *= aimdDecreaseFactor
+	if al.limit < 1 {
+		al.limit = 1
+	}
+	al.slots.setLimit(int64(al.limit))
+}
+
+// onSuccess additively increases the allowed concurrency.
+func (al *aimdLimiter) onSuccess() {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	if al.limit >= al.max {
+		return
+	}
+
+	al.limit += 1 / al.limit
+	if al.limit > al.max {
+		al.limit = al.max
+	}
+	al.slots.setLimit(int64(al.limit))
+}
+
+func (al *aimdLimiter) currentLimit() int64 {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	return int64(al.limit)
+}
+
+// isIdempotentCommand reports whether sending the command more than once has
+// the same effect as sending it once, which makes it safe to retry.
+func isIdempotentCommand(command string) bool {
+	switch command {
+	case commandIncr, commandDecr, commandAppend, commandSnapshot:
+		return false
+	default:
+		return true
+	}
+}
+
+// busyBackoff returns the jittered exponential delay before retrying a
+// command rejected with RespServerBusy.
+func busyBackoff(opts *Options, attempt int64) time.Duration {
+	backoff := opts.RetryBackoff << attempt
+	if backoff <= 0 || backoff > MaxRetryBackoff {
+		backoff = MaxRetryBackoff
+	}
+
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// sleepContext waits for the given duration unless the context is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,145 @@
+package universum
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAIMDLimiter(t *testing.T) {
+	limiter := newAIMDLimiter(16)
+
+	limiter.onBusy()
+	limiter.onBusy()
+	if limit := limiter.currentLimit(); limit != 4 {
+		t.Fatalf("Expected limit to be halved twice to 4, got %d", limit)
+	}
+
+	for i := 0; i < 10; i++ {
+		limiter.onBusy()
+	}
+	if limit := limiter.currentLimit(); limit != 1 {
+		t.Fatalf("Expected limit to bottom out at 1, got %d", limit)
+	}
+
+	for i := 0; i < 3; i++ {
+		limiter.onSuccess()
+	}
+	if limit := limiter.currentLimit(); limit != 2 {
+		t.Fatalf("Expected limit to grow additively to 2, got %d", limit)
+	}
+
+	for i := 0; i < 1000; i++ {
+		limiter.onSuccess()
+	}
+	if limit := limiter.currentLimit(); limit != 16 {
+		t.Fatalf("Expected limit to be capped at 16, got %d", limit)
+	}
+}
+
+func newBusyServerClient(t *testing.T, busyReplies int64, code int64) (*Client, *int64) {
+	var calls int64
+
+	client := newFakeClient(t, func(cmd []interface{}) interface{} {
+		if atomic.AddInt64(&calls, 1) <= busyReplies {
+			return fakeReply(nil, code, "")
+		}
+		if cmd[0] == commandIncr {
+			return fakeReply(int64(1), RespRecordUpdated, "")
+		}
+		return fakeReply(nil, RespRecordNotFound, "")
+	}, func(opts *Options) {
+		opts.RetryBackoff = time.Millisecond
+		opts.AdaptiveBackpressure = true
+		opts.ShutdownRedialDelay = 100 * time.Millisecond
+	})
+
+	return client, &calls
+}
+
+func TestClient_BusyRetriesIdempotentCommands(t *testing.T) {
+	client, calls := newBusyServerClient(t, 2, RespServerBusy)
+
+	result, err := client.Get(context.Background(), "key")
+	if err != nil || result.Code != RespRecordNotFound {
+		t.Fatalf("Expected GET to succeed after retries, got %v, %v", result, err)
+	}
+	if atomic.LoadInt64(calls) != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", atomic.LoadInt64(calls))
+	}
+	if limit := client.backpressure.currentLimit(); limit >= client.opts.ConnPoolsize {
+		t.Fatalf("Expected busy replies to reduce the allowed concurrency, got %d", limit)
+	}
+}
+
+func TestClient_BusyDoesNotRetryNonIdempotentCommands(t *testing.T) {
+	client, calls := newBusyServerClient(t, 1, RespServerBusy)
+
+	if _, err := client.Increment(context.Background(), "key", 1); !errors.Is(err, ErrServerBusy) {
+		t.Fatalf("Expected server busy error, got %v", err)
+	}
+	if atomic.LoadInt64(calls) != 1 {
+		t.Fatalf("Expected a single attempt, got %d", atomic.LoadInt64(calls))
+	}
+	if limit := client.backpressure.currentLimit(); limit >= client.opts.ConnPoolsize {
+		t.Fatalf("Expected the busy reply to reduce the allowed concurrency, got %d", limit)
+	}
+}
+
+func TestClient_BusyRetriesExhaustedReduceLimit(t *testing.T) {
+	client := newFakeClient(t, func(cmd []interface{}) interface{} {
+		return fakeReply(nil, RespServerBusy, "")
+	}, func(opts *Options) {
+		opts.MaxRetries = 1
+		opts.RetryBackoff = time.Millisecond
+		opts.AdaptiveBackpressure = true
+	})
+
+	if _, err := client.Get(context.Background(), "key"); !errors.Is(err, ErrServerBusy) {
+		t.Fatalf("Expected server busy error, got %v", err)
+	}
+
+	expected := newAIMDLimiter(client.opts.ConnPoolsize)
+	expected.onBusy()
+	expected.onBusy()
+	if limit := client.backpressure.currentLimit(); limit != expected.currentLimit() {
+		t.Fatalf("Expected both busy replies to reduce the limit to %d, got %d", expected.currentLimit(), limit)
+	}
+}
+
+func TestClient_BusyWithoutBackpressure(t *testing.T) {
+	client := newFakeClient(t, func(cmd []interface{}) interface{} {
+		return fakeReply(nil, RespServerBusy, "server is busy")
+	}, nil)
+
+	result, err := client.Get(context.Background(), "key")
+	if err != nil || result.Code != RespServerBusy {
+		t.Fatalf("Expected the busy reply to be returned as a result, got %v, %v", result, err)
+	}
+}
+
+func TestClient_ServerShuttingDown(t *testing.T) {
+	client, calls := newBusyServerClient(t, 1, RespServerShuttingDown)
+	ctx := context.Background()
+
+	if _, err := client.Get(ctx, "key"); !errors.Is(err, ErrServerShuttingDown) {
+		t.Fatalf("Expected shutting down error, got %v", err)
+	}
+	if client.pool.Len() != 0 {
+		t.Fatalf("Expected connection to be drained, got %d live", client.pool.Len())
+	}
+
+	if _, err := client.Get(ctx, "key"); !errors.Is(err, ErrServerShuttingDown) {
+		t.Fatalf("Expected fail fast while the node is unhealthy, got %v", err)
+	}
+	if atomic.LoadInt64(calls) != 1 {
+		t.Fatalf("Expected no request to reach the server while unhealthy, got %d", atomic.LoadInt64(calls))
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if result, err := client.Get(ctx, "key"); err != nil || result.Code != RespRecordNotFound {
+		t.Fatalf("Expected redial after the delay to succeed, got %v, %v", result, err)
+	}
+}
@@ -0,0 +1,186 @@
+package universum
+
+import (
+	"bufio"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// benchmarkHandler answers the benchmarked commands with fixed replies
+func benchmarkHandler(value string) fakeHandler {
+	return func(cmd []interface{}) interface{} {
+		switch cmd[0] {
+		case commandGet:
+			return fakeReply(map[string]interface{}{"Value": value}, RespRecordFound, "")
+		case commandSet:
+			return fakeReply(true, RespRecordUpdated, "")
+		case commandIncr:
+			return fakeReply(int64(1), RespRecordUpdated, "")
+		case commandMget:
+			values := make(map[string]interface{})
+			for _, key := range cmd[1].([]interface{}) {
+				values[key.(string)] = map[string]interface{}{"Code": RespRecordFound, "Value": value}
+			}
+			return fakeReply(values, RespMgetCompleted, "")
+		default:
+			return fakeReply("OK", RespPingSuccess, "")
+		}
+	}
+}
+
+// newBenchmarkClient creates a client of a local fake server answering with values of the given size
+func newBenchmarkClient(b *testing.B, valueSize, poolSize int) *Client {
+	b.Helper()
+
+	return newFakeClient(b, benchmarkHandler(strings.Repeat("x", valueSize)), func(opts *Options) {
+		opts.ConnPoolsize = int64(poolSize)
+	})
+}
+
+func BenchmarkEncodeResp(b *testing.B) {
+	keys := make([]string, 10)
+	for i := range keys {
+		keys[i] = "key-" + strconv.Itoa(i)
+	}
+
+	commands := []struct {
+		name    string
+		command []interface{}
+	}{
+		{"GET", []interface{}{commandGet, "key"}},
+		{"SET/16B", []interface{}{commandSet, "key", strings.Repeat("x", 16), int64(0)}},
+		{"SET/4KiB", []interface{}{commandSet, "key", strings.Repeat("x", 4096), int64(0)}},
+		{"MGET/10", []interface{}{commandMget, keys}},
+	}
+
+	for _, tc := range commands {
+		b.Run(tc.name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := encodeResp(tc.command); err != nil {
+					b.Fatalf("Expected no error, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkReadReply(b *testing.B) {
+	opts := &Options{MaxReplySize: DefaultMaxReplySize, MaxReplyDepth: DefaultMaxReplyDepth}
+
+	for _, size := range []int{16, 4096, 64 * 1024} {
+		encoded, err := encodeResp(fakeReply(map[string]interface{}{"Value": strings.Repeat("x", size)}, RespRecordFound, ""))
+		if err != nil {
+			b.Fatalf("Expected no error, got %v", err)
+		}
+		reply := []byte(encoded + remoteByteDelimiter)
+
+		b.Run(strconv.Itoa(size)+"B", func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(reply)))
+
+			netconn := &scriptedConn{}
+			conn := &Conn{netconn: netconn, reader: bufio.NewReader(netconn), writer: bufio.NewWriter(netconn)}
+
+			for i := 0; i < b.N; i++ {
+				netconn.pending.Write(reply)
+
+				buffer, err := readUntilDelimiter(context.Background(), conn, opts, remoteByteDelimiter)
+				if err != nil {
+					b.Fatalf("Expected no error, got %v", err)
+				}
+
+				if _, err := parseReply(buffer.Bytes(), opts); err != nil {
+					b.Fatalf("Expected no error, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkPool_GetConn(b *testing.B) {
+	opts := mockPoolOptions(b)
+	opts.ConnPoolsize = 4
+
+	pool, err := newConnPool(opts, nil)
+	if err != nil {
+		b.Fatalf("Expected to create conn pool, got error: %v", err)
+	}
+	b.Cleanup(func() { pool.Close() })
+
+	ctx := context.Background()
+
+	// more goroutines than connections, so that checkouts contend for the pool
+	b.SetParallelism(4)
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			conn, err := pool.GetConn(ctx)
+			if err != nil {
+				b.Errorf("Expected no error, got %v", err)
+				return
+			}
+			pool.ReleaseConn(ctx, conn)
+		}
+	})
+}
+
+func BenchmarkClient_RoundTrip(b *testing.B) {
+	ctx := context.Background()
+
+	b.Run("GET", func(b *testing.B) {
+		client := newBenchmarkClient(b, 16, 1)
+		b.ResetTimer()
+
+		for i := 0; i < b.N; i++ {
+			if _, err := client.Get(ctx, "key"); err != nil {
+				b.Fatalf("Expected no error, got %v", err)
+			}
+		}
+	})
+
+	for _, size := range []int{16, 4096, 64 * 1024} {
+		b.Run("SET/"+strconv.Itoa(size)+"B", func(b *testing.B) {
+			client := newBenchmarkClient(b, 16, 1)
+			value := strings.Repeat("x", size)
+			b.SetBytes(int64(size))
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := client.Set(ctx, "key", value, 0); err != nil {
+					b.Fatalf("Expected no error, got %v", err)
+				}
+			}
+		})
+	}
+
+	b.Run("MGET/10", func(b *testing.B) {
+		client := newBenchmarkClient(b, 16, 1)
+		keys := []string{"k0", "k1", "k2", "k3", "k4", "k5", "k6", "k7", "k8", "k9"}
+		b.ResetTimer()
+
+		for i := 0; i < b.N; i++ {
+			if _, err := client.MGet(ctx, keys); err != nil {
+				b.Fatalf("Expected no error, got %v", err)
+			}
+		}
+	})
+
+	b.Run("GET/parallel", func(b *testing.B) {
+		client := newBenchmarkClient(b, 16, DefaultConnPoolsize)
+		b.ResetTimer()
+
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if _, err := client.Get(ctx, "key"); err != nil {
+					b.Errorf("Expected no error, got %v", err)
+					return
+				}
+			}
+		})
+	})
+}
@@ -0,0 +1,254 @@
+package universum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of the client's circuit breaker.
+type BreakerState int32
+
+const (
+	// BreakerClosed lets every command through while failures are counted.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails every command immediately until the cool-down elapses.
+	BreakerOpen
+	// BreakerHalfOpen lets a single PING probe decide whether to close or re-open.
+	BreakerHalfOpen
+)
+
+// String returns the name of the breaker state.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int32(s))
+	}
+}
+
+// BreakerStats is a point-in-time snapshot of the circuit breaker.
+type BreakerStats struct {
+	State               BreakerState
+	Requests            int64
+	Failures            int64
+	ConsecutiveFailures int64
+	Trips               int64
+	Rejected            int64
+	LastStateChange     time.Time
+}
+
+// circuitBreaker trips open once the consecutive failures or the failure rate
+// within the current window cross their thresholds, and probes the server
+// with a PING after the cool-down before letting traffic through again.
+type circuitBreaker struct {
+	mu sync.Mutex
+
+	consecutiveThreshold int64
+	failureRate          float64
+	minRequests          int64
+	window               time.Duration
+	cooldown             time.Duration
+
+	probe    func(ctx context.Context) error
+	onChange func(from, to BreakerState)
+	now      func() time.Time
+
+	state       BreakerState
+	windowStart time.Time
+	requests    int64
+	failures    int64
+	consecutive int64
+	openedAt    time.Time
+	trips       int64
+	rejected    int64
+	changedAt   time.Time
+	pending     [][2]BreakerState
+}
+
+func newCircuitBreaker(opts *Options, probe func(ctx context.Context) error) *circuitBreaker {
+	now := time.Now()
+
+	return &circuitBreaker{
+		consecutiveThreshold: opts.BreakerConsecutiveFailures,
+		failureRate:          opts.BreakerFailureRate,
+		minRequests:          opts.BreakerMinRequests,
+		window:               opts.BreakerWindow,
+		cooldown:             opts.BreakerCooldown,
+		probe:                probe,
+		onChange:             opts.OnBreakerStateChange,
+		now:                  time.Now,
+		state:                BreakerClosed,
+		windowStart:          now,
+		changedAt:            now,
+	}
+}
+
+// allow decides whether a command may be sent. Once the cool-down of an open
+// breaker elapses, the calling goroutine runs the half-open probe itself and
+// proceeds only if the probe succeeds; everyone else is rejected meanwhile. A
+// probe cut short by the caller's own context does not count as a failure.
+func (cb *circuitBreaker) allow(ctx context.Context) error {
+	cb.mu.Lock()
+
+	switch cb.state {
+	case BreakerClosed:
+		cb.mu.Unlock()
+		return nil
+
+	case BreakerOpen:
+		if cb.now().Sub(cb.openedAt) >= cb.cooldown {
+			cb.transition(BreakerHalfOpen)
+			cb.mu.Unlock()
+			cb.notify()
+			return cb.runProbe(ctx)
+		}
+	}
+
+	cb.rejected++
+	cb.mu.Unlock()
+	return fmt.Errorf("circuit breaker is open: %w", ErrCircuitOpen)
+}
+
+func (cb *circuitBreaker) runProbe(ctx context.Context) error {
+	err := cb.probe(ctx)
+
+	defer cb.notify()
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if err != nil && ctx.Err() != nil {
+		// the caller gave up, which says nothing about the server: reopen
+		// without restarting the cool-down, so the next caller probes again
+		cb.transition(BreakerOpen)
+		return fmt.Errorf("circuit breaker probe abandoned [%v]: %w", err, ctx.Err())
+	}
+
+	if err != nil {
+		cb.rejected++
+		cb.trip()
+		return fmt.Errorf("circuit breaker probe failed [%v]: %w", err, ErrCircuitOpen)
+	}
+
+	cb.resetCounts()
+	cb.transition(BreakerClosed)
+	return nil
+}
+
+// record accounts the outcome of a command which was allowed through.
+func (cb *circuitBreaker) record(err error) {
+	failed := isBreakerFailure(err)
+
+	defer cb.notify()
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state != BreakerClosed {
+		return
+	}
+
+	now := cb.now()
+	if cb.window > 0 && now.Sub(cb.windowStart) >= cb.window {
+		cb.resetCounts()
+	}
+
+	cb.requests++
+	if !failed {
+		cb.consecutive = 0
+		return
+	}
+
+	cb.failures++
+	cb.consecutive++
+
+	if cb.consecutiveThreshold > 0 && cb.consecutive >= cb.consecutiveThreshold {
+		cb.trip()
+		return
+	}
+
+	if cb.failureRate > 0 && cb.requests >= cb.minRequests &&
+		float64(cb.failures)/float64(cb.requests) >= cb.failureRate {
+		cb.trip()
+	}
+}
+
+func (cb *circuitBreaker) stats() BreakerStats {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return BreakerStats{
+		State:               cb.state,
+		Requests:            cb.requests,
+		Failures:            cb.failures,
+		ConsecutiveFailures: cb.consecutive,
+		Trips:               cb.trips,
+		Rejected:            cb.rejected,
+		LastStateChange:     cb.changedAt,
+	}
+}
+
+// trip opens the breaker, it must be called with mu held.
+func (cb *circuitBreaker) trip() {
+	cb.trips++
+	cb.openedAt = cb.now()
+	cb.resetCounts()
+	cb.transition(BreakerOpen)
+}
+
+// resetCounts starts a new counting window, it must be called with mu held.
+func (cb *circuitBreaker) resetCounts() {
+	cb.windowStart = cb.now()
+	cb.requests = 0
+	cb.failures = 0
+	cb.consecutive = 0
+}
+
+// transition moves to the given state and reports the change, it must be
+// called with mu held.
+func (cb *circuitBreaker) transition(to BreakerState) {
+	from := cb.state
+	if from == to {
+		return
+	}
+
+	cb.state = to
+	cb.changedAt = cb.now()
+
+	if cb.onChange != nil {
+		cb.pending = append(cb.pending, [2]BreakerState{from, to})
+	}
+}
+
+// notify reports the queued state transitions to the callback outside of the
+// lock, so that the callback may safely inspect the client.
+func (cb *circuitBreaker) notify() {
+	cb.mu.Lock()
+	pending := cb.pending
+	cb.pending = nil
+	cb.mu.Unlock()
+
+	for _, change := range pending {
+		cb.onChange(change[0], change[1])
+	}
+}
+
+// isBreakerFailure reports whether err indicates that the server could not be
+// reached or the connection to it failed, as opposed to a rejected request or
+// a command abandoned by the caller.
+func isBreakerFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	return errors.Is(err, ErrConnectionDialFailed) ||
+		errors.Is(err, ErrConnectionDialTimeout) ||
+		errors.Is(err, ErrServerShuttingDown) ||
+		isConnBroken(err)
+}
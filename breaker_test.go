@@ -0,0 +1,206 @@
+package universum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestBreaker creates a breaker driven by a manual clock and probe outcome
+func newTestBreaker(probeErr *error) (*circuitBreaker, *time.Time, *[]string) {
+	opts := &Options{}
+	opts.Init()
+
+	clock := time.Now()
+	var changes []string
+
+	opts.OnBreakerStateChange = func(from, to BreakerState) {
+		changes = append(changes, from.String()+"->"+to.String())
+	}
+
+	cb := newCircuitBreaker(opts, func(ctx context.Context) error { return *probeErr })
+	cb.now = func() time.Time { return clock }
+
+	return cb, &clock, &changes
+}
+
+func TestCircuitBreaker_ConsecutiveFailures(t *testing.T) {
+	var probeErr error
+	cb, clock, changes := newTestBreaker(&probeErr)
+	ctx := context.Background()
+
+	for i := int64(0); i < DefaultBreakerConsecutiveFailures; i++ {
+		if err := cb.allow(ctx); err != nil {
+			t.Fatalf("Expected closed breaker to allow requests, got %v", err)
+		}
+		cb.record(ErrConnectionDialFailed)
+	}
+
+	if err := cb.allow(ctx); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected open breaker to reject requests, got %v", err)
+	}
+
+	probeErr = ErrConnectionDialFailed
+	*clock = clock.Add(DefaultBreakerCooldown)
+	if err := cb.allow(ctx); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected failed probe to keep the breaker open, got %v", err)
+	}
+
+	probeErr = nil
+	*clock = clock.Add(DefaultBreakerCooldown)
+	if err := cb.allow(ctx); err != nil {
+		t.Fatalf("Expected successful probe to close the breaker, got %v", err)
+	}
+
+	expected := []string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"}
+	if !reflect.DeepEqual(*changes, expected) {
+		t.Fatalf("Expected transitions %v, got %v", expected, *changes)
+	}
+
+	stats := cb.stats()
+	if stats.State != BreakerClosed || stats.Trips != 2 || stats.Rejected != 2 {
+		t.Fatalf("Unexpected breaker stats: %+v", stats)
+	}
+}
+
+func TestCircuitBreaker_ProbeAbandonedByCaller(t *testing.T) {
+	var probeErr error
+	cb, clock, _ := newTestBreaker(&probeErr)
+
+	for i := int64(0); i < DefaultBreakerConsecutiveFailures; i++ {
+		cb.allow(context.Background())
+		cb.record(ErrConnectionDialFailed)
+	}
+
+	// the probe fails only because the caller's deadline expired
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	probeErr = fmt.Errorf("probe timed out [%w]: %w", context.DeadlineExceeded, ErrSocketReadFailed)
+	*clock = clock.Add(DefaultBreakerCooldown)
+	if err := cb.allow(ctx); !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected the caller's deadline error, got %v", err)
+	}
+
+	probeErr = nil
+	if err := cb.allow(context.Background()); err != nil {
+		t.Fatalf("Expected the next caller to probe without another cool-down, got %v", err)
+	}
+
+	if stats := cb.stats(); stats.State != BreakerClosed || stats.Trips != 1 {
+		t.Fatalf("Expected the abandoned probe not to trip the breaker again, got %+v", stats)
+	}
+}
+
+func TestCircuitBreaker_FailureRate(t *testing.T) {
+	var probeErr error
+	cb, clock, _ := newTestBreaker(&probeErr)
+
+	for i := 0; i < DefaultBreakerMinRequests; i++ {
+		if i%2 == 1 {
+			cb.record(ErrSocketReadFailed)
+		} else {
+			cb.record(nil)
+		}
+	}
+
+	if cb.stats().State != BreakerOpen {
+		t.Fatalf("Expected breaker to trip on failure rate, got %v", cb.stats().State)
+	}
+
+	cb.resetCounts()
+	cb.state = BreakerClosed
+	for i := 0; i < DefaultBreakerMinRequests; i++ {
+		cb.record(ErrSocketReadFailed)
+		cb.record(nil)
+		cb.record(nil)
+	}
+
+	if cb.stats().State != BreakerClosed {
+		t.Fatalf("Expected failure rate below threshold to keep breaker closed, got %v", cb.stats().State)
+	}
+
+	cb.resetCounts()
+	for i := 0; i < DefaultBreakerMinRequests/2-1; i++ {
+		cb.record(ErrSocketReadFailed)
+		cb.record(nil)
+	}
+	*clock = clock.Add(DefaultBreakerWindow)
+	cb.record(nil)
+
+	if stats := cb.stats(); stats.State != BreakerClosed || stats.Requests != 1 {
+		t.Fatalf("Expected counts to restart with a new window, got %+v", stats)
+	}
+}
+
+func TestCircuitBreaker_IgnoresNonNetworkErrors(t *testing.T) {
+	var probeErr error
+	cb, _, _ := newTestBreaker(&probeErr)
+
+	for i := 0; i < 50; i++ {
+		cb.record(ErrServerRejectedRequest)
+		cb.record(context.Canceled)
+	}
+
+	if stats := cb.stats(); stats.State != BreakerClosed || stats.Failures != 0 {
+		t.Fatalf("Expected non-network errors to be ignored, got %+v", stats)
+	}
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to reserve a local address: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	var mu sync.Mutex
+	var states []BreakerState
+
+	opts := mockOptions()
+	opts.HostAddr = addr
+	opts.MaxRetries = 1
+	opts.BreakerEnabled = true
+	opts.BreakerConsecutiveFailures = 2
+	opts.BreakerCooldown = time.Hour
+	opts.OnBreakerStateChange = func(from, to BreakerState) {
+		mu.Lock()
+		states = append(states, to)
+		mu.Unlock()
+	}
+
+	client, err := NewClient(opts)
+	if err != nil {
+		t.Fatalf("Expected no error while creating client, got %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := client.Get(ctx, "key"); !errors.Is(err, ErrConnectionDialFailed) {
+			t.Fatalf("Expected dial failure, got %v", err)
+		}
+	}
+
+	if _, err := client.Get(ctx, "key"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected circuit open error, got %v", err)
+	}
+
+	stats := client.BreakerStats()
+	if stats.State != BreakerOpen || stats.Trips != 1 || stats.Rejected != 1 {
+		t.Fatalf("Unexpected breaker stats: %+v", stats)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(states, []BreakerState{BreakerOpen}) {
+		t.Fatalf("Expected a single transition to open, got %v", states)
+	}
+}
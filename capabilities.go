@@ -0,0 +1,195 @@
+package universum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// capabilityRegistry caches the commands supported by the server. They are
+// taken from the capabilities advertised in the HELLO handshake, or, for
+// servers which predate it, from the command names listed by HELP. If
+// neither is available the registry stays unknown and no command is gated.
+type capabilityRegistry struct {
+	mu         sync.Mutex
+	loaded     bool
+	loading    chan struct{} // closed when the HELP query in flight completes
+	generation uint64        // bumped by a refresh, so results of older queries are dropped
+	commands   map[string]bool
+}
+
+// capabilitySet builds a set of upper-cased command names
+func capabilitySet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[strings.ToUpper(name)] = true
+	}
+	return set
+}
+
+// parseHelpCommands extracts the command names from the HELP text, i.e. the
+// lines starting with one of the known command names. Banners and prose are
+// skipped, so a text listing no known command yields nil.
+func parseHelpCommands(text string) []string {
+	known := capabilitySet(Commands())
+
+	var commands []string
+	for _, line := range strings.Split(text, "\n") {
+		words := strings.FieldsFunc(line, func(r rune) bool {
+			return !unicode.IsLetter(r)
+		})
+		if len(words) > 0 && known[words[0]] {
+			commands = append(commands, words[0])
+		}
+	}
+
+	return commands
+}
+
+// capabilities returns the set of commands supported by the server, or nil if
+// it cannot be determined. Errors are only returned for transient failures,
+// which are not cached. Concurrent callers share a single HELP query, which
+// runs without holding the registry lock.
+func (c *Client) capabilities(ctx context.Context) (map[string]bool, error) {
+	for {
+		if info := c.hello.serverInfo(); info != nil && len(info.Capabilities) > 0 {
+			return capabilitySet(info.Capabilities), nil
+		}
+
+		c.caps.mu.Lock()
+		if c.caps.loaded {
+			commands := c.caps.commands
+			c.caps.mu.Unlock()
+			return commands, nil
+		}
+
+		if loading := c.caps.loading; loading != nil {
+			c.caps.mu.Unlock()
+
+			select {
+			case <-loading:
+				continue
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		loading := make(chan struct{})
+		c.caps.loading = loading
+		generation := c.caps.generation
+		c.caps.mu.Unlock()
+
+		commands, cache, err := c.queryHelp(ctx)
+
+		c.caps.mu.Lock()
+		c.caps.loading = nil
+		if cache && generation == c.caps.generation {
+			c.caps.loaded = true
+			c.caps.commands = commands
+		}
+		c.caps.mu.Unlock()
+		close(loading)
+
+		return commands, err
+	}
+}
+
+// queryHelp learns the supported commands from HELP. cache reports whether
+// the outcome is final for this server, as opposed to a transient failure or
+// capabilities advertised by a handshake in the meantime.
+func (c *Client) queryHelp(ctx context.Context) (commands map[string]bool, cache bool, err error) {
+	// sent on behalf of the caller's command, so it bypasses the breaker, the
+	// limiters and the slow log like the handshake does
+	result, err := executeCommand(ctx, c, newCommandTrace(), commandHelp)
+
+	// the HELP round trip establishes a connection, whose handshake may have
+	// advertised the capabilities in the meantime
+	if info := c.hello.serverInfo(); info != nil && len(info.Capabilities) > 0 {
+		return capabilitySet(info.Capabilities), false, nil
+	}
+
+	switch {
+	case err == nil && result.code == RespHelpContentOk:
+		text, _ := result.value.(string)
+		if names := parseHelpCommands(text); len(names) > 0 {
+			commands = capabilitySet(names)
+		}
+		return commands, true, nil
+
+	case err == nil, errors.Is(err, ErrServerRejectedRequest):
+		// the server offers no way to list its commands
+		return nil, true, nil
+
+	default:
+		return nil, false, err
+	}
+}
+
+// requireCapability fails with ErrUnsupportedByServer if the server is known
+// not to support the command. If support cannot be determined the command is
+// let through and left to the server.
+func (c *Client) requireCapability(ctx context.Context, command string) error {
+	commands, err := c.capabilities(ctx)
+	if err != nil || commands == nil || commands[command] {
+		return nil
+	}
+
+	return fmt.Errorf("command %s is not supported by the server: %w", command, ErrUnsupportedByServer)
+}
+
+// Capabilities returns the commands supported by the server, as advertised in
+// the HELLO handshake or listed by HELP.
+//
+// Parameters:
+// - ctx: Context for managing timeouts and cancellations.
+//
+// Returns:
+// - []string: The sorted command names, or nil if the server does not report them.
+// - error: Returns an error if the server could not be queried.
+func (c *Client) Capabilities(ctx context.Context) ([]string, error) {
+	commands, err := c.capabilities(ctx)
+	if err != nil || commands == nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// Supports reports whether the server supports the command. Commands are
+// assumed to be supported if the server does not report its capabilities.
+//
+// Parameters:
+// - ctx: Context for managing timeouts and cancellations.
+// - command: The command name, e.g. "SNAPSHOT".
+//
+// Returns:
+// - bool: false only if the server is known not to support the command.
+// - error: Returns an error if the server could not be queried.
+func (c *Client) Supports(ctx context.Context, command string) (bool, error) {
+	commands, err := c.capabilities(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return commands == nil || commands[strings.ToUpper(command)], nil
+}
+
+// RefreshCapabilities discards the capabilities learned from HELP, so that
+// they are queried again, e.g. after the server was upgraded.
+func (c *Client) RefreshCapabilities() {
+	c.caps.mu.Lock()
+	c.caps.loaded = false
+	c.caps.commands = nil
+	c.caps.generation++
+	c.caps.mu.Unlock()
+}
@@ -0,0 +1,238 @@
+package universum
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newCapabilityClient starts a fake server which advertises the given
+// capabilities in its HELLO reply, or answers HELP with the given text when
+// advertised is nil, and counts the commands it receives
+func newCapabilityClient(t *testing.T, advertised []string, help string) (*Client, map[string]*int64) {
+	counts := map[string]*int64{}
+	for _, command := range []string{commandHelp, commandSnapshot, commandExpire, commandTtl} {
+		counts[command] = new(int64)
+	}
+
+	server := newFakeServerPerConn(t, func() fakeHandler {
+		return func(cmd []interface{}) interface{} {
+			command := cmd[0].(string)
+			if count, ok := counts[command]; ok {
+				atomic.AddInt64(count, 1)
+			}
+
+			switch command {
+			case commandHello:
+				if advertised == nil {
+					return fakeReply(nil, RespInvalidCmdInput, "unknown command")
+				}
+				return fakeReply(map[string]interface{}{"version": "2.0.0", "protocol": int64(1),
+					"capabilities": advertised}, RespHelloSuccess, "OK")
+			case commandHelp:
+				if help == "" {
+					return fakeReply(nil, RespInvalidCmdInput, "unknown command")
+				}
+				return fakeReply(help, RespHelpContentOk, "")
+			case commandSnapshot:
+				return fakeReply(nil, RespSnapshotStarted, "")
+			case commandExpire:
+				return fakeReply(true, RespRecordUpdated, "")
+			case commandTtl:
+				return fakeReply(int64(10), RespRecordFound, "")
+			}
+			return fakeReply(nil, RespRecordNotFound, "")
+		}
+	})
+
+	return newClientFor(t, server, nil), counts
+}
+
+func TestCapabilities_FromHandshake(t *testing.T) {
+	client, counts := newCapabilityClient(t, []string{"GET", "SET", "EXPIRE", "TTL"}, "")
+	ctx := context.Background()
+
+	if _, err := client.Snapshot(ctx); !errors.Is(err, ErrUnsupportedByServer) {
+		t.Fatalf("Expected ErrUnsupportedByServer, got %v", err)
+	}
+	if atomic.LoadInt64(counts[commandSnapshot]) != 0 {
+		t.Fatal("Expected the unsupported command not to be sent")
+	}
+
+	if result, err := client.Expire(ctx, "key", 10); err != nil || !result.Success {
+		t.Fatalf("Expected supported EXPIRE to succeed, got %v, %v", result, err)
+	}
+
+	capabilities, err := client.Capabilities(ctx)
+	if err != nil || !reflect.DeepEqual(capabilities, []string{"EXPIRE", "GET", "SET", "TTL"}) {
+		t.Fatalf("Expected advertised capabilities, got %v, %v", capabilities, err)
+	}
+}
+
+func TestCapabilities_FromHelp(t *testing.T) {
+	help := "Available commands:\n  GET key\n  SET key value [ttl]\n  TTL key\n  SNAPSHOT\n"
+	client, counts := newCapabilityClient(t, nil, help)
+	ctx := context.Background()
+
+	if _, err := client.Expire(ctx, "key", 10); !errors.Is(err, ErrUnsupportedByServer) {
+		t.Fatalf("Expected ErrUnsupportedByServer, got %v", err)
+	}
+
+	if result, err := client.Snapshot(ctx); err != nil || !result.Started {
+		t.Fatalf("Expected SNAPSHOT listed by HELP to succeed, got %v, %v", result, err)
+	}
+
+	if supported, err := client.Supports(ctx, "ttl"); err != nil || !supported {
+		t.Fatalf("Expected TTL to be supported, got %v, %v", supported, err)
+	}
+
+	if calls := atomic.LoadInt64(counts[commandHelp]); calls != 1 {
+		t.Fatalf("Expected HELP to be queried once, got %d", calls)
+	}
+
+	client.RefreshCapabilities()
+	if _, err := client.Capabilities(ctx); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if calls := atomic.LoadInt64(counts[commandHelp]); calls != 2 {
+		t.Fatalf("Expected HELP to be queried again after a refresh, got %d", calls)
+	}
+}
+
+func TestCapabilities_HelpBypassesLimits(t *testing.T) {
+	client := newFakeClient(t, func(cmd []interface{}) interface{} {
+		switch cmd[0] {
+		case commandHelp:
+			return fakeReply("SNAPSHOT\n", RespHelpContentOk, "")
+		case commandSnapshot:
+			return fakeReply(nil, RespSnapshotStarted, "")
+		}
+		return fakeReply(nil, RespRecordNotFound, "")
+	}, func(opts *Options) {
+		opts.AdminRateLimit = 0.001
+		opts.AdminRateBurst = 1
+		opts.LimiterFailFast = true
+		opts.SlowLogThreshold = time.Nanosecond
+	})
+
+	if result, err := client.Snapshot(context.Background()); err != nil || !result.Started {
+		t.Fatalf("Expected the HELP fallback not to use up the admin rate limit, got %v, %v", result, err)
+	}
+
+	for _, entry := range client.SlowLog() {
+		if entry.Command == commandHelp {
+			t.Fatal("Expected the HELP fallback not to be recorded in the slow log")
+		}
+	}
+}
+
+func TestCapabilities_Unknown(t *testing.T) {
+	client, counts := newCapabilityClient(t, nil, "")
+	ctx := context.Background()
+
+	if result, err := client.Snapshot(ctx); err != nil || !result.Started {
+		t.Fatalf("Expected commands to be sent when capabilities are unknown, got %v, %v", result, err)
+	}
+	if _, err := client.TTL(ctx, "key"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if capabilities, err := client.Capabilities(ctx); err != nil || capabilities != nil {
+		t.Fatalf("Expected unknown capabilities, got %v, %v", capabilities, err)
+	}
+	if calls := atomic.LoadInt64(counts[commandHelp]); calls != 1 {
+		t.Fatalf("Expected an unsupported HELP not to be retried, got %d", calls)
+	}
+}
+
+func TestCapabilities_HelpWithoutKnownCommands(t *testing.T) {
+	client, counts := newCapabilityClient(t, nil, "NOTE: OK\nSee the DOCS for details\n")
+	ctx := context.Background()
+
+	if result, err := client.Snapshot(ctx); err != nil || !result.Started {
+		t.Fatalf("Expected commands to be sent when HELP lists no known command, got %v, %v", result, err)
+	}
+	if capabilities, err := client.Capabilities(ctx); err != nil || capabilities != nil {
+		t.Fatalf("Expected unknown capabilities, got %v, %v", capabilities, err)
+	}
+	if calls := atomic.LoadInt64(counts[commandHelp]); calls != 1 {
+		t.Fatalf("Expected HELP to be queried once, got %d", calls)
+	}
+}
+
+func TestCapabilities_ConcurrentHelp(t *testing.T) {
+	release := make(chan struct{})
+	var calls int64
+
+	client := newFakeClient(t, func(cmd []interface{}) interface{} {
+		if cmd[0] == commandHelp {
+			atomic.AddInt64(&calls, 1)
+			<-release
+			return fakeReply("GET key\nSET key value\n", RespHelpContentOk, "")
+		}
+		return fakeReply(nil, RespRecordNotFound, "")
+	}, nil)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	results := make([][]string, 4)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = client.Capabilities(ctx)
+		}(i)
+	}
+
+	for atomic.LoadInt64(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.Supports(waitCtx, "GET"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected a waiting caller to honour its context, got %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		client.RefreshCapabilities()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Expected the registry not to be locked during the HELP round trip")
+	}
+
+	close(release)
+	wg.Wait()
+
+	for _, capabilities := range results {
+		if !reflect.DeepEqual(capabilities, []string{"GET", "SET"}) {
+			t.Fatalf("Expected the commands listed by HELP, got %v", capabilities)
+		}
+	}
+	// the refresh drops the result of the query in flight, so it is queried once more
+	if calls := atomic.LoadInt64(&calls); calls != 2 {
+		t.Fatalf("Expected concurrent callers to share the HELP queries, got %d", calls)
+	}
+}
+
+func TestParseHelpCommands(t *testing.T) {
+	commands := parseHelpCommands("Supported commands:\nGET <key>: fetch a value\n  - MSET k1 v1 ...\n" +
+		"use ttl=0 for no EXPIRY, e.g. a\nNOTE: OK\nGetting started: SET a value first\n")
+	expected := []string{"GET", "MSET"}
+
+	if !reflect.DeepEqual(commands, expected) {
+		t.Fatalf("Expected %v, got %v", expected, commands)
+	}
+
+	if commands := parseHelpCommands("NOTE: OK\n"); commands != nil {
+		t.Fatalf("Expected no commands from a banner, got %v", commands)
+	}
+}
@@ -6,6 +6,7 @@ package universum
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"strconv"
 	"sync"
@@ -21,13 +22,31 @@ var ncmu sync.Mutex
 // - id: A unique identifier for the client, typically encoded as a base64 string.
 // - pool: A pool of connections to manage database interactions.
 // - opts: Configuration options provided to the client.
+// - slowlog: Recorder of commands exceeding the slow-log threshold, nil when disabled.
+// - limiter: Per command class rate limits and in-flight limit, nil when disabled.
+// - hedger: Hedging policy for latency-critical reads, nil when disabled.
+// - backpressure: AIMD concurrency limit adapting to busy responses, nil when disabled.
+// - breaker: Circuit breaker guarding the pool, nil when disabled.
+// - hello: Handshake announcing the client on new connections and recording the server info.
+// - caps: Commands supported by the server, learned from HELP if the handshake does not advertise them.
+// - warmupErr: The aggregated error of a failed pool warm-up, if any.
 type Client struct {
-	id   string
-	pool *connPool
-	opts *Options
+	id           string
+	pool         *connPool
+	opts         *Options
+	slowlog      *slowLog
+	limiter      *commandLimiter
+	hedger       *hedger
+	backpressure *aimdLimiter
+	breaker      *circuitBreaker
+	hello        *handshaker
+	caps         capabilityRegistry
+	warmupErr    error
 }
 
 // Get retrieves the value of a specified key from the Universum database.
+// When hedging is configured, a GET still pending after the hedge delay is
+// duplicated on another pooled connection and the first reply wins.
 //
 // Parameters:
 // - ctx: Context for managing timeouts and cancellations.
@@ -37,7 +56,7 @@ type Client struct {
 // - *GetResult: The result of the GET operation.
 // - error: Returns an error if the command fails.
 func (c *Client) Get(ctx context.Context, key string) (*GetResult, error) {
-	result, err := sendCommand(ctx, c, commandGet, key)
+	result, err := sendHedgedCommand(ctx, c, commandGet, key)
 	if err != nil {
 		return nil, err
 	}
@@ -70,9 +89,8 @@ func (c *Client) Set(ctx context.Context, key string, value interface{}, ttl int
 		return nil, fmt.Errorf("cannot execute write op in read-only client: %w", ErrClientReadonly)
 	}
 
-	if !isWriteableDatatype(value) {
-		return nil, fmt.Errorf("provided datatype is not supported for write operations, "+
-			"only int|float|bool|string|[]interface{} types are supported: %w", ErrInvalidDatatype)
+	if !IsWriteableDatatype(value) {
+		return nil, errNotWriteable
 	}
 
 	result, err := sendCommand(ctx, c, commandSet, key, value, ttl)
@@ -236,6 +254,7 @@ func (c *Client) Append(ctx context.Context, key string, value string) (*AppendR
 }
 
 // MGet retrieves the values of multiple keys from the Universum database.
+// Like Get, it is hedged when hedging is configured.
 //
 // Parameters:
 // - ctx: Context for managing timeouts and cancellations.
@@ -249,7 +268,7 @@ func (c *Client) MGet(ctx context.Context, keys []string) (*MGetResult, error) {
 		return nil, fmt.Errorf("MGET requires at least one key: %w", ErrInvalidRequest)
 	}
 
-	result, err := sendCommand(ctx, c, commandMget, keys)
+	result, err := sendHedgedCommand(ctx, c, commandMget, keys)
 
 	if err != nil {
 		return nil, err
@@ -283,6 +302,12 @@ func (c *Client) MSet(ctx context.Context, kv map[string]interface{}) (*MSetResu
 		return nil, fmt.Errorf("MSET requires at least one key: %w", ErrInvalidRequest)
 	}
 
+	for key, value := range kv {
+		if !IsWriteableDatatype(value) {
+			return nil, fmt.Errorf("value of key %s: %w", key, errNotWriteable)
+		}
+	}
+
 	result, err := sendCommand(ctx, c, commandMset, kv)
 
 	if err != nil {
@@ -395,8 +420,12 @@ func (c *Client) Ping(ctx context.Context) (*PingResult, error) {
 //
 // Returns:
 // - *TTLResult: The result of the TTL operation.
-// - error: Returns an error if the command fails.
+// - error: Returns an error if the command fails, or ErrUnsupportedByServer.
 func (c *Client) TTL(ctx context.Context, key string) (*TTLResult, error) {
+	if err := c.requireCapability(ctx, commandTtl); err != nil {
+		return nil, err
+	}
+
 	result, err := sendCommand(ctx, c, commandTtl, key)
 
 	if err != nil {
@@ -422,12 +451,16 @@ func (c *Client) TTL(ctx context.Context, key string) (*TTLResult, error) {
 //
 // Returns:
 // - *ExpireResult: The result of the EXPIRE operation.
-// - error: Returns an error if the command fails.
+// - error: Returns an error if the command fails, or ErrUnsupportedByServer.
 func (c *Client) Expire(ctx context.Context, key string, ttl int64) (*ExpireResult, error) {
 	if c.opts.IsReadonly {
 		return nil, fmt.Errorf("cannot execute write op in read-only client: %w", ErrClientReadonly)
 	}
 
+	if err := c.requireCapability(ctx, commandExpire); err != nil {
+		return nil, err
+	}
+
 	result, err := sendCommand(ctx, c, commandExpire, key, ttl)
 
 	if err != nil {
@@ -444,32 +477,260 @@ func (c *Client) Expire(ctx context.Context, key string, ttl int64) (*ExpireResu
 	return nil, fmt.Errorf("response value found in unexpected format: %w", ErrMalformedResponseReceived)
 }
 
+// Snapshot asks the server to start writing a snapshot of the database to disk.
+//
+// Parameters:
+// - ctx: Context for managing timeouts and cancellations.
+//
+// Returns:
+// - *SnapshotResult: The result of the SNAPSHOT operation.
+// - error: Returns an error if the command fails, or ErrUnsupportedByServer.
+func (c *Client) Snapshot(ctx context.Context) (*SnapshotResult, error) {
+	if err := c.requireCapability(ctx, commandSnapshot); err != nil {
+		return nil, err
+	}
+
+	result, err := sendCommand(ctx, c, commandSnapshot)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &SnapshotResult{
+		Started: result.code == RespSnapshotStarted,
+		Code:    result.code,
+	}, nil
+}
+
+// Help retrieves the list of commands and their usage from the server.
+//
+// Parameters:
+// - ctx: Context for managing timeouts and cancellations.
+//
+// Returns:
+// - *HelpResult: The result of the HELP operation.
+// - error: Returns an error if the command fails.
+func (c *Client) Help(ctx context.Context) (*HelpResult, error) {
+	result, err := sendCommand(ctx, c, commandHelp)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if help, ok := result.value.(string); ok {
+		return &HelpResult{
+			Raw:  help,
+			Code: result.code,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("response value found in unexpected format: %w", ErrMalformedResponseReceived)
+}
+
+// Close closes the client, stopping the pool's background maintenance and
+// closing every connection. The client cannot be used after it is closed.
+//
+// Returns:
+// - error: Returns ErrConnectionPoolClosed if the client was already closed.
+func (c *Client) Close() error {
+	return c.pool.Close()
+}
+
+// WarmupErr returns the aggregated error of the pool warm-up performed by NewClient.
+// A non-nil value means the client started degraded: it is usable, but some or all
+// of the connections requested by Options.WarmupConns could not be established.
+//
+// Returns:
+// - error: The warm-up error wrapping ErrPoolWarmupFailed, or nil.
+func (c *Client) WarmupErr() error {
+	return c.warmupErr
+}
+
+// TLSReloadErr returns the error of the last failed reload of rotated TLS files.
+// While it is non-nil the client keeps dialing with the previously loaded
+// certificates, which may be about to expire.
+//
+// Returns:
+// - error: The reload error, or nil if TLS is disabled or the last reload succeeded.
+func (c *Client) TLSReloadErr() error {
+	return c.pool.tls.reloadErr()
+}
+
+// warmUp eagerly establishes Options.WarmupConns connections, verifying each one
+// with a PING if Options.WarmupPing is set.
+func (c *Client) warmUp() error {
+	var verify func(conn connInterface) error
+
+	if c.opts.WarmupPing {
+		verify = func(conn connInterface) error {
+			result, err := execOnConn(context.Background(), conn, c.opts, newCommandTrace(), commandPing)
+			if err != nil {
+				return err
+			}
+
+			return checkPingResult(result)
+		}
+	}
+
+	if err := c.pool.warmUp(c.opts.WarmupConns, verify); err != nil {
+		return fmt.Errorf("%w: %w", ErrPoolWarmupFailed, err)
+	}
+
+	return nil
+}
+
+// ServerInfo returns the server version, protocol version and capabilities
+// negotiated by the HELLO handshake of the most recently established connection.
+//
+// Returns:
+// - *ServerInfo: The server information, or nil if no connection was established
+// yet or the server predates the handshake.
+func (c *Client) ServerInfo() *ServerInfo {
+	return c.hello.serverInfo()
+}
+
+// BreakerStats returns a snapshot of the circuit breaker state and counters.
+//
+// Returns:
+// - BreakerStats: The breaker statistics, reporting BreakerClosed if the breaker is disabled.
+func (c *Client) BreakerStats() BreakerStats {
+	if c.breaker == nil {
+		return BreakerStats{State: BreakerClosed}
+	}
+
+	return c.breaker.stats()
+}
+
+// probe sends a PING bypassing the circuit breaker, it is used by the breaker
+// to decide whether the server is reachable again.
+func (c *Client) probe(ctx context.Context) error {
+	result, err := executeCommand(ctx, c, newCommandTrace(), commandPing)
+	if err != nil {
+		return err
+	}
+
+	return checkPingResult(result)
+}
+
+// checkPingResult verifies that a PING was answered successfully.
+func checkPingResult(result *CommandResult) error {
+	if result.code != RespPingSuccess {
+		return fmt.Errorf("unexpected ping response code %d: %w", result.code, ErrMalformedResponseReceived)
+	}
+
+	return nil
+}
+
+// SlowLog returns the commands which exceeded Options.SlowLogThreshold, newest first.
+// At most Options.SlowLogMaxLen entries are retained; older ones are overwritten.
+//
+// Returns:
+// - []SlowLogEntry: The recorded slow commands, or nil if the slow log is disabled.
+func (c *Client) SlowLog() []SlowLogEntry {
+	if c.slowlog == nil {
+		return nil
+	}
+
+	return c.slowlog.snapshot()
+}
+
+// ResetSlowLog discards all the entries recorded in the slow log.
+func (c *Client) ResetSlowLog() {
+	if c.slowlog != nil {
+		c.slowlog.reset()
+	}
+}
+
 // NewClient creates and returns a new Client instance based on the provided options.
 // The function initializes the connection pool and generates a unique client ID.
 //
+// If Options.WarmupConns is set, that many connections are dialed in parallel before
+// returning. A failed warm-up is fatal only when Options.WarmupRequired is set; otherwise
+// the degraded client is returned and the failure is reported by Client.WarmupErr.
+//
+// The client keeps its own copy of the options with defaults applied, so the caller's
+// struct is left untouched. With Options.StrictValidation set, options which would be
+// clamped or are otherwise invalid are rejected with the error from Options.Validate.
+//
 // Parameters:
 // - opts: A pointer to the Options struct that contains the necessary configurations.
 //
 // Returns:
 // - *Client: A pointer to the newly created Client instance.
-// - error: Returns an error if the connection pool could not be initialized.
+// - error: Returns an error if the options are rejected or the connection pool could not be initialized.
 func NewClient(opts *Options) (*Client, error) {
+	client, err := newClient(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// warm up outside of ncmu, so that an unreachable server does not hold up
+	// other clients being created
+	if client.opts.WarmupConns > 0 {
+		client.warmupErr = client.warmUp()
+
+		if client.warmupErr != nil && client.opts.WarmupRequired {
+			return nil, errors.Join(client.warmupErr, client.pool.Close())
+		}
+	}
+
+	return client, nil
+}
+
+// newClient builds the client and its connection pool without dialing.
+func newClient(opts *Options) (*Client, error) {
 	ncmu.Lock()
 	defer ncmu.Unlock()
 
+	if opts.StrictValidation {
+		if err := opts.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	// work on a copy so that the caller's options are never mutated
+	clientOpts := *opts
+	opts = &clientOpts
 	opts.Init()
-	connPool, err := newConnPool(opts)
+
+	currTime := time.Now().UnixNano()
+	uniqueId := base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(int(currTime))))
+
+	hello := newHandshaker(opts, uniqueId)
+	connPool, err := newConnPool(opts, hello)
 
 	if err != nil {
 		return nil, err
 	}
 
-	currTime := time.Now().UnixNano()
-	uniqueId := base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(int(currTime))))
+	client := &Client{
+		id:    uniqueId,
+		opts:  opts,
+		pool:  connPool,
+		hello: hello,
+	}
 
-	return &Client{
-		id:   uniqueId,
-		opts: opts,
-		pool: connPool,
-	}, nil
+	if opts.SlowLogThreshold > 0 {
+		client.slowlog = newSlowLog(opts.SlowLogThreshold, opts.SlowLogMaxLen, opts.OnSlowCommand)
+	}
+
+	client.limiter = newCommandLimiter(opts)
+
+	if opts.HedgeDelay > 0 || opts.HedgePercentile > 0 {
+		client.hedger = newHedger(opts)
+	}
+
+	if opts.AdaptiveBackpressure {
+		maxConcurrency := opts.ConnPoolsize
+		if opts.MaxInFlight > 0 && opts.MaxInFlight < maxConcurrency {
+			maxConcurrency = opts.MaxInFlight
+		}
+		client.backpressure = newAIMDLimiter(maxConcurrency)
+	}
+
+	if opts.BreakerEnabled {
+		client.breaker = newCircuitBreaker(opts, client.probe)
+	}
+
+	return client, nil
 }
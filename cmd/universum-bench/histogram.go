@@ -0,0 +1,91 @@
+package main
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+// subBuckets is the number of buckets per power of two, bounding the error
+// of the reported percentiles to 1/subBuckets
+const subBuckets = 16
+
+// histogram counts latencies in log-linear buckets, so that percentiles of
+// any number of commands are computed in constant memory.
+type histogram struct {
+	counts [64 * subBuckets]int64
+	total  int64
+	sum    time.Duration
+	max    time.Duration
+}
+
+// bucketOf returns the bucket of a latency in nanoseconds
+func bucketOf(v uint64) int {
+	if v < subBuckets {
+		return int(v)
+	}
+
+	// keep the leading bit and the next four bits of the value
+	shift := bits.Len64(v) - 5
+	return (shift+1)*subBuckets + int((v>>shift)&(subBuckets-1))
+}
+
+// bucketValue returns the smallest latency in nanoseconds counted by a bucket
+func bucketValue(bucket int) uint64 {
+	if bucket < subBuckets {
+		return uint64(bucket)
+	}
+
+	shift := bucket/subBuckets - 1
+	return (subBuckets | uint64(bucket%subBuckets)) << shift
+}
+
+func (h *histogram) record(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+
+	h.counts[bucketOf(uint64(d))]++
+	h.total++
+	h.sum += d
+	h.max = max(h.max, d)
+}
+
+func (h *histogram) merge(other *histogram) {
+	for i, count := range other.counts {
+		h.counts[i] += count
+	}
+
+	h.total += other.total
+	h.sum += other.sum
+	h.max = max(h.max, other.max)
+}
+
+// percentile returns the latency below which the given percentage of the
+// recorded latencies fall
+func (h *histogram) percentile(p float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+
+	rank := int64(math.Ceil(p / 100 * float64(h.total)))
+	rank = max(rank, 1)
+
+	var seen int64
+	for bucket, count := range h.counts {
+		seen += count
+		if seen >= rank {
+			return min(time.Duration(bucketValue(bucket)), h.max)
+		}
+	}
+
+	return h.max
+}
+
+func (h *histogram) mean() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+
+	return h.sum / time.Duration(h.total)
+}
@@ -0,0 +1,422 @@
+// Command universum-bench generates load against a Universum server and
+// reports the throughput and latency percentiles of every command, like
+// redis-benchmark does for Redis:
+//
+//	universum-bench -url universum://localhost:11191 -c 50 -n 100000 -mix get=80,set=20
+//
+// Every client runs a configurable mix of GET, SET, INCR and MGET commands on
+// random keys of the keyspace. The client library does not pipeline commands
+// on a connection, so a pipeline depth of P keeps P commands of every client
+// in flight at once, each on its own pooled connection.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cshekharsharma/universum-client-go"
+)
+
+// errUsage reports invalid command line flags
+var errUsage = errors.New("invalid usage")
+
+const (
+	keyPrefix     = "bench:key:"
+	counterPrefix = "bench:counter:"
+)
+
+// command runs one benchmarked command for a worker
+type command func(ctx context.Context, client *universum.Client, w *worker) error
+
+var commands = map[string]command{
+	"get": func(ctx context.Context, client *universum.Client, w *worker) error {
+		_, err := client.Get(ctx, w.key(keyPrefix))
+		return err
+	},
+	"set": func(ctx context.Context, client *universum.Client, w *worker) error {
+		_, err := client.Set(ctx, w.key(keyPrefix), w.value, 0)
+		return err
+	},
+	"incr": func(ctx context.Context, client *universum.Client, w *worker) error {
+		_, err := client.Increment(ctx, w.key(counterPrefix), 1)
+		return err
+	},
+	"mget": func(ctx context.Context, client *universum.Client, w *worker) error {
+		for i := range w.keys {
+			w.keys[i] = w.key(keyPrefix)
+		}
+		_, err := client.MGet(ctx, w.keys)
+		return err
+	},
+}
+
+// weightedCommand is a command of the mix with its share of the requests
+type weightedCommand struct {
+	name   string
+	weight int
+}
+
+// config holds the command line flags
+type config struct {
+	url       string
+	clients   int
+	requests  int64
+	duration  time.Duration
+	pipeline  int
+	valueSize int
+	keyspace  int
+	mgetKeys  int
+	poolSize  int
+	prefill   bool
+	mix       []weightedCommand
+}
+
+func main() {
+	cfg, err := parseFlags(os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, cfg, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// parseFlags parses the command line flags into a config
+func parseFlags(args []string, output io.Writer) (*config, error) {
+	cfg := &config{}
+	var mix string
+
+	flags := flag.NewFlagSet("universum-bench", flag.ContinueOnError)
+	flags.SetOutput(output)
+	flags.StringVar(&cfg.url, "url", "universum://localhost:11191", "connection URL of the server, see universum.ParseURL")
+	flags.IntVar(&cfg.clients, "c", 50, "number of parallel clients")
+	flags.Int64Var(&cfg.requests, "n", 100000, "total number of requests")
+	flags.DurationVar(&cfg.duration, "duration", 0, "run for the given duration instead of a number of requests")
+	flags.IntVar(&cfg.pipeline, "P", 1, "commands in flight per client")
+	flags.IntVar(&cfg.valueSize, "d", 3, "size of the SET values in bytes")
+	flags.IntVar(&cfg.keyspace, "r", 10000, "number of distinct keys")
+	flags.IntVar(&cfg.mgetKeys, "mget-keys", 10, "number of keys of every MGET")
+	flags.IntVar(&cfg.poolSize, "pool", 0, "connection pool size, clients times pipeline depth if 0 and not given in the URL")
+	flags.BoolVar(&cfg.prefill, "prefill", true, "set every key of the keyspace before the benchmark")
+	flags.StringVar(&mix, "mix", "get=50,set=30,incr=10,mget=10", "weighted mix of the get, set, incr and mget commands")
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments %v: %w", flags.Args(), errUsage)
+	}
+
+	var err error
+	if cfg.mix, err = parseMix(mix); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case cfg.clients < 1:
+		return nil, fmt.Errorf("-c must be at least 1: %w", errUsage)
+	case cfg.pipeline < 1:
+		return nil, fmt.Errorf("-P must be at least 1: %w", errUsage)
+	case cfg.requests < 1 && cfg.duration <= 0:
+		return nil, fmt.Errorf("-n or -duration must be positive: %w", errUsage)
+	case cfg.valueSize < 0:
+		return nil, fmt.Errorf("-d must not be negative: %w", errUsage)
+	case cfg.keyspace < 1:
+		return nil, fmt.Errorf("-r must be at least 1: %w", errUsage)
+	case cfg.mgetKeys < 1:
+		return nil, fmt.Errorf("-mget-keys must be at least 1: %w", errUsage)
+	case cfg.poolSize < 0:
+		return nil, fmt.Errorf("-pool must not be negative: %w", errUsage)
+	}
+
+	return cfg, nil
+}
+
+// parseMix parses a comma separated list of commands with optional weights, e.g. get=80,set=20
+func parseMix(mix string) ([]weightedCommand, error) {
+	var parsed []weightedCommand
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(mix, ",") {
+		name, weight, hasWeight := strings.Cut(strings.TrimSpace(part), "=")
+		name = strings.ToLower(name)
+
+		if _, ok := commands[name]; !ok {
+			return nil, fmt.Errorf("unknown command %q in -mix, expected get, set, incr or mget: %w", name, errUsage)
+		}
+
+		if seen[name] {
+			return nil, fmt.Errorf("command %q given twice in -mix: %w", name, errUsage)
+		}
+		seen[name] = true
+
+		w := 1
+		if hasWeight {
+			var err error
+			if w, err = strconv.Atoi(weight); err != nil || w < 0 {
+				return nil, fmt.Errorf("invalid weight %q of %s in -mix: %w", weight, name, errUsage)
+			}
+		}
+
+		if w > 0 {
+			parsed = append(parsed, weightedCommand{name: name, weight: w})
+		}
+	}
+
+	if len(parsed) == 0 {
+		return nil, fmt.Errorf("-mix has no command with a positive weight: %w", errUsage)
+	}
+
+	return parsed, nil
+}
+
+// worker issues the commands of one in-flight slot of a client and records their latencies
+type worker struct {
+	cfg   *config
+	rnd   *rand.Rand
+	value string
+	keys  []string
+
+	latencies []histogram
+	errors    []int64
+	firstErr  error
+}
+
+func newWorker(cfg *config, seed int64) *worker {
+	return &worker{
+		cfg:       cfg,
+		rnd:       rand.New(rand.NewSource(seed)),
+		value:     strings.Repeat("x", cfg.valueSize),
+		keys:      make([]string, cfg.mgetKeys),
+		latencies: make([]histogram, len(cfg.mix)),
+		errors:    make([]int64, len(cfg.mix)),
+	}
+}
+
+// key returns a random key of the keyspace
+func (w *worker) key(prefix string) string {
+	return prefix + strconv.Itoa(w.rnd.Intn(w.cfg.keyspace))
+}
+
+// pick returns the index of a random command of the mix, by weight
+func (w *worker) pick(totalWeight int) int {
+	n := w.rnd.Intn(totalWeight)
+	for i, cmd := range w.cfg.mix {
+		if n < cmd.weight {
+			return i
+		}
+		n -= cmd.weight
+	}
+
+	return len(w.cfg.mix) - 1
+}
+
+// run issues commands until next reports that the benchmark is over
+func (w *worker) run(client *universum.Client, next func() bool) {
+	totalWeight := 0
+	for _, cmd := range w.cfg.mix {
+		totalWeight += cmd.weight
+	}
+
+	// in-flight commands complete after an interrupt, so that they are not counted as errors
+	ctx := context.Background()
+
+	for next() {
+		i := w.pick(totalWeight)
+
+		start := time.Now()
+		err := commands[w.cfg.mix[i].name](ctx, client, w)
+		w.latencies[i].record(time.Since(start))
+
+		if err != nil {
+			w.errors[i]++
+			if w.firstErr == nil {
+				w.firstErr = err
+			}
+		}
+	}
+}
+
+// newClient creates the client of the benchmark, sizing the pool for the
+// commands in flight unless the pool size is configured
+func newClient(cfg *config) (*universum.Client, error) {
+	opts, err := universum.ParseURL(cfg.url)
+	if err != nil {
+		return nil, err
+	}
+
+	switch parsed, _ := url.Parse(cfg.url); {
+	case cfg.poolSize > 0:
+		opts.ConnPoolsize = int64(cfg.poolSize)
+	case !parsed.Query().Has("pool_size"):
+		opts.ConnPoolsize = int64(cfg.clients * cfg.pipeline)
+	}
+
+	client, err := universum.NewClient(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := client.Ping(context.Background()); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to reach %s: %w", opts.HostAddr, err)
+	}
+
+	return client, nil
+}
+
+// prefill sets every key of the keyspace, so that GET and MGET find values
+func prefill(ctx context.Context, cfg *config, client *universum.Client) error {
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	errs := make([]error, cfg.clients)
+	value := strings.Repeat("x", cfg.valueSize)
+
+	for c := 0; c < cfg.clients; c++ {
+		wg.Add(1)
+		go func(c int) {
+			defer wg.Done()
+
+			for key := next.Add(1) - 1; key < int64(cfg.keyspace) && ctx.Err() == nil; key = next.Add(1) - 1 {
+				if _, err := client.Set(ctx, keyPrefix+strconv.FormatInt(key, 10), value, 0); err != nil {
+					errs[c] = err
+					return
+				}
+			}
+		}(c)
+	}
+
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("failed to prefill the keyspace: %w", err)
+	}
+
+	return ctx.Err()
+}
+
+// run runs the benchmark and writes the report to out
+func run(ctx context.Context, cfg *config, out io.Writer) error {
+	client, err := newClient(cfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if cfg.prefill {
+		if err := prefill(ctx, cfg, client); err != nil {
+			return err
+		}
+	}
+
+	var issued atomic.Int64
+	var deadline time.Time
+	if cfg.duration > 0 {
+		deadline = time.Now().Add(cfg.duration)
+	}
+
+	next := func() bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		if !deadline.IsZero() {
+			return time.Now().Before(deadline)
+		}
+		return issued.Add(1) <= cfg.requests
+	}
+
+	workers := make([]*worker, cfg.clients*cfg.pipeline)
+	var wg sync.WaitGroup
+	start := time.Now()
+
+	for i := range workers {
+		workers[i] = newWorker(cfg, start.UnixNano()+int64(i))
+
+		wg.Add(1)
+		go func(w *worker) {
+			defer wg.Done()
+			w.run(client, next)
+		}(workers[i])
+	}
+
+	wg.Wait()
+	report(out, cfg, workers, time.Since(start))
+
+	return nil
+}
+
+// report writes the throughput and latency percentiles of every command and of all commands
+func report(out io.Writer, cfg *config, workers []*worker, elapsed time.Duration) {
+	latencies := make([]histogram, len(cfg.mix))
+	errs := make([]int64, len(cfg.mix))
+	var all histogram
+	var allErrs int64
+	var firstErr error
+
+	for _, w := range workers {
+		for i := range cfg.mix {
+			latencies[i].merge(&w.latencies[i])
+			errs[i] += w.errors[i]
+		}
+		if firstErr == nil {
+			firstErr = w.firstErr
+		}
+	}
+
+	for i := range cfg.mix {
+		all.merge(&latencies[i])
+		allErrs += errs[i]
+	}
+
+	fmt.Fprintf(out, "%d requests completed in %s\n", all.total, elapsed.Round(time.Millisecond))
+	fmt.Fprintf(out, "%d parallel clients, pipeline depth %d, %d byte values, keyspace %d\n\n",
+		cfg.clients, cfg.pipeline, cfg.valueSize, cfg.keyspace)
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "COMMAND\tREQUESTS\tERRORS\tREQ/S\tAVG\tP50\tP90\tP99\tP99.9\tMAX\t")
+
+	row := func(name string, h *histogram, errors int64) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%s\t%s\t%s\t%s\t%s\t%s\t\n", name, h.total, errors,
+			float64(h.total)/elapsed.Seconds(), formatLatency(h.mean()), formatLatency(h.percentile(50)),
+			formatLatency(h.percentile(90)), formatLatency(h.percentile(99)), formatLatency(h.percentile(99.9)),
+			formatLatency(h.max))
+	}
+
+	for i, cmd := range cfg.mix {
+		row(strings.ToUpper(cmd.name), &latencies[i], errs[i])
+	}
+	row("ALL", &all, allErrs)
+	tw.Flush()
+
+	if firstErr != nil {
+		fmt.Fprintf(out, "\nfirst error: %v\n", firstErr)
+	}
+}
+
+// formatLatency formats a latency in milliseconds
+func formatLatency(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64) + "ms"
+}
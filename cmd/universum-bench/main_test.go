@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cshekharsharma/universum-client-go/universumtest"
+)
+
+func TestHistogram_Percentile(t *testing.T) {
+	h := &histogram{}
+	for i := 1; i <= 1000; i++ {
+		h.record(time.Duration(i) * time.Microsecond)
+	}
+
+	for _, tc := range []struct {
+		percentile float64
+		expected   time.Duration
+	}{
+		{50, 500 * time.Microsecond},
+		{90, 900 * time.Microsecond},
+		{99, 990 * time.Microsecond},
+		{100, 1000 * time.Microsecond},
+	} {
+		got := h.percentile(tc.percentile)
+		if got > tc.expected || got < tc.expected-tc.expected/subBuckets {
+			t.Fatalf("Expected p%v within %d%% below %v, got %v", tc.percentile, 100/subBuckets, tc.expected, got)
+		}
+	}
+
+	if h.max != time.Millisecond || h.mean() != 500500*time.Nanosecond {
+		t.Fatalf("Expected max 1ms and mean 500.5µs, got %v and %v", h.max, h.mean())
+	}
+
+	merged := &histogram{}
+	merged.merge(h)
+	merged.merge(h)
+
+	if merged.total != 2000 || merged.percentile(50) != h.percentile(50) {
+		t.Fatalf("Expected merged histograms to keep their percentiles, got %d latencies with p50 %v", merged.total, merged.percentile(50))
+	}
+}
+
+func TestHistogram_Buckets(t *testing.T) {
+	for _, v := range []uint64{0, 1, 15, 16, 31, 32, 1000, 123456789, 1 << 62} {
+		bucket := bucketOf(v)
+		lower := bucketValue(bucket)
+
+		if lower > v || v-lower > v/subBuckets {
+			t.Fatalf("Expected bucket of %d to start at most %d%% below it, got %d", v, 100/subBuckets, lower)
+		}
+
+		if bucketOf(lower) != bucket {
+			t.Fatalf("Expected %d to fall into its own bucket %d, got %d", lower, bucket, bucketOf(lower))
+		}
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	cfg, err := parseFlags([]string{"-c", "8", "-P", "4", "-d", "128", "-mix", "GET=3, set=1,incr=0"}, io.Discard)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if cfg.clients != 8 || cfg.pipeline != 4 || cfg.valueSize != 128 {
+		t.Fatalf("Expected 8 clients, pipeline 4 and 128 byte values, got %d, %d and %d", cfg.clients, cfg.pipeline, cfg.valueSize)
+	}
+
+	expected := []weightedCommand{{"get", 3}, {"set", 1}}
+	if len(cfg.mix) != len(expected) || cfg.mix[0] != expected[0] || cfg.mix[1] != expected[1] {
+		t.Fatalf("Expected mix %v, got %v", expected, cfg.mix)
+	}
+
+	for _, args := range [][]string{
+		{"-mix", "get,del"},
+		{"-mix", "get=-1"},
+		{"-mix", "get,get"},
+		{"-mix", "set=0"},
+		{"-c", "0"},
+		{"-P", "0"},
+		{"-n", "0"},
+		{"extra"},
+	} {
+		if _, err := parseFlags(args, io.Discard); !errors.Is(err, errUsage) {
+			t.Fatalf("Expected errUsage for %v, got %v", args, err)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	srv := universumtest.NewServer(t)
+
+	cfg, err := parseFlags([]string{
+		"-url", "universum://" + srv.Addr(),
+		"-c", "4", "-P", "2", "-n", "400", "-d", "8192", "-r", "50", "-mget-keys", "5",
+		"-mix", "get=4,set=3,incr=2,mget=1",
+	}, io.Discard)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := run(context.Background(), cfg, &out); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	report := out.String()
+	if !strings.HasPrefix(report, "400 requests completed in ") {
+		t.Fatalf("Expected 400 completed requests, got:\n%s", report)
+	}
+
+	for _, name := range []string{"GET", "SET", "INCR", "MGET", "ALL"} {
+		if !strings.Contains(report, "\n"+name+" ") && !strings.Contains(report, " "+name+" ") {
+			t.Fatalf("Expected a row for %s, got:\n%s", name, report)
+		}
+	}
+
+	if strings.Contains(report, "first error") {
+		t.Fatalf("Expected no errors, got:\n%s", report)
+	}
+}
+
+func TestRun_Unreachable(t *testing.T) {
+	cfg, err := parseFlags([]string{"-url", "universum://127.0.0.1:1?dial_timeout=100ms&max_retries=1"}, io.Discard)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := run(context.Background(), cfg, io.Discard); err == nil {
+		t.Fatal("Expected an error for an unreachable server")
+	}
+}
@@ -0,0 +1,417 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cshekharsharma/universum-client-go"
+)
+
+// errSyntax reports a command line which cannot be run
+var errSyntax = errors.New("syntax error")
+
+// token is a word of a command line, remembering whether it was quoted so
+// that quoted values are always sent as strings
+type token struct {
+	text   string
+	quoted bool
+}
+
+// command runs a command of the server with its arguments, writing the result to out
+type command struct {
+	usage   string
+	minArgs int
+	maxArgs int // -1 for any number
+	run     func(ctx context.Context, client *universum.Client, args []token, out io.Writer) error
+}
+
+// commands are the commands of the shell, one for every command of universum.Commands()
+var commands = map[string]command{
+	"PING": {"", 0, 0, func(ctx context.Context, client *universum.Client, args []token, out io.Writer) error {
+		result, err := client.Ping(ctx)
+		if err != nil {
+			return err
+		}
+		return printResult(out, result.Code, quote(result.Message))
+	}},
+
+	"GET": {"key", 1, 1, func(ctx context.Context, client *universum.Client, args []token, out io.Writer) error {
+		result, err := client.Get(ctx, args[0].text)
+		if err != nil {
+			return err
+		}
+		return printResult(out, result.Code, formatValue(result.Value))
+	}},
+
+	"SET": {"key value [ttl]", 2, 3, func(ctx context.Context, client *universum.Client, args []token, out io.Writer) error {
+		var ttl int64
+		if len(args) == 3 {
+			var err error
+			if ttl, err = parseInt("ttl", args[2]); err != nil {
+				return err
+			}
+		}
+
+		result, err := client.Set(ctx, args[0].text, parseValue(args[1]), ttl)
+		if err != nil {
+			return err
+		}
+		return printResult(out, result.Code, formatValue(result.Success))
+	}},
+
+	"EXISTS": {"key", 1, 1, func(ctx context.Context, client *universum.Client, args []token, out io.Writer) error {
+		result, err := client.Exists(ctx, args[0].text)
+		if err != nil {
+			return err
+		}
+		return printResult(out, result.Code, formatValue(result.Found))
+	}},
+
+	"DELETE": {"key", 1, 1, func(ctx context.Context, client *universum.Client, args []token, out io.Writer) error {
+		result, err := client.Delete(ctx, args[0].text)
+		if err != nil {
+			return err
+		}
+		return printResult(out, result.Code, formatValue(result.Deleted))
+	}},
+
+	"INCR": {"key [offset]", 1, 2, func(ctx context.Context, client *universum.Client, args []token, out io.Writer) error {
+		offset, err := optionalOffset(args)
+		if err != nil {
+			return err
+		}
+
+		result, err := client.Increment(ctx, args[0].text, offset)
+		if err != nil {
+			return err
+		}
+		return printResult(out, result.Code, formatValue(result.NewValue))
+	}},
+
+	"DECR": {"key [offset]", 1, 2, func(ctx context.Context, client *universum.Client, args []token, out io.Writer) error {
+		offset, err := optionalOffset(args)
+		if err != nil {
+			return err
+		}
+
+		result, err := client.Decrement(ctx, args[0].text, offset)
+		if err != nil {
+			return err
+		}
+		return printResult(out, result.Code, formatValue(result.NewValue))
+	}},
+
+	"APPEND": {"key value", 2, 2, func(ctx context.Context, client *universum.Client, args []token, out io.Writer) error {
+		result, err := client.Append(ctx, args[0].text, args[1].text)
+		if err != nil {
+			return err
+		}
+		return printResult(out, result.Code, formatValue(result.ContentLength))
+	}},
+
+	"MGET": {"key [key ...]", 1, -1, func(ctx context.Context, client *universum.Client, args []token, out io.Writer) error {
+		result, err := client.MGet(ctx, texts(args))
+		if err != nil {
+			return err
+		}
+
+		values := make(map[string]string, len(result.Values))
+		for key, entry := range result.Values {
+			// every entry holds the value with its own response code
+			if fields, ok := entry.(map[string]interface{}); ok {
+				code, _ := fields["Code"].(int64)
+				values[key] = formatValue(fields["Value"]) + " (" + universum.RespCodeName(code) + ")"
+			} else {
+				values[key] = formatValue(entry)
+			}
+		}
+		return printResult(out, result.Code, formatMap(values))
+	}},
+
+	"MSET": {"key value [key value ...]", 2, -1, func(ctx context.Context, client *universum.Client, args []token, out io.Writer) error {
+		if len(args)%2 != 0 {
+			return fmt.Errorf("MSET expects pairs of keys and values: %w", errSyntax)
+		}
+
+		kv := make(map[string]interface{}, len(args)/2)
+		for i := 0; i < len(args); i += 2 {
+			kv[args[i].text] = parseValue(args[i+1])
+		}
+
+		result, err := client.MSet(ctx, kv)
+		if err != nil {
+			return err
+		}
+		return printResult(out, result.Code, formatBools(result.Successes))
+	}},
+
+	"MDELETE": {"key [key ...]", 1, -1, func(ctx context.Context, client *universum.Client, args []token, out io.Writer) error {
+		result, err := client.MDelete(ctx, texts(args))
+		if err != nil {
+			return err
+		}
+		return printResult(out, result.Code, formatBools(result.Deletions))
+	}},
+
+	"TTL": {"key", 1, 1, func(ctx context.Context, client *universum.Client, args []token, out io.Writer) error {
+		result, err := client.TTL(ctx, args[0].text)
+		if err != nil {
+			return err
+		}
+		return printResult(out, result.Code, formatValue(int64(result.TTL/time.Second)))
+	}},
+
+	"EXPIRE": {"key ttl", 2, 2, func(ctx context.Context, client *universum.Client, args []token, out io.Writer) error {
+		ttl, err := parseInt("ttl", args[1])
+		if err != nil {
+			return err
+		}
+
+		result, err := client.Expire(ctx, args[0].text, ttl)
+		if err != nil {
+			return err
+		}
+		return printResult(out, result.Code, formatValue(result.Success))
+	}},
+
+	"SNAPSHOT": {"", 0, 0, func(ctx context.Context, client *universum.Client, args []token, out io.Writer) error {
+		result, err := client.Snapshot(ctx)
+		if err != nil {
+			return err
+		}
+		return printResult(out, result.Code, formatValue(result.Started))
+	}},
+
+	"INFO": {"", 0, 0, func(ctx context.Context, client *universum.Client, args []token, out io.Writer) error {
+		result, err := client.Info(ctx)
+		if err != nil {
+			return err
+		}
+		return printResult(out, result.Code, strings.TrimRight(result.Raw, "\n"))
+	}},
+
+	"HELP": {"", 0, 0, func(ctx context.Context, client *universum.Client, args []token, out io.Writer) error {
+		result, err := client.Help(ctx)
+		if err != nil {
+			return err
+		}
+		return printResult(out, result.Code, strings.TrimRight(result.Raw, "\n"))
+	}},
+}
+
+// completions returns the commands starting with the prefix, ignoring case
+func completions(prefix string) []string {
+	var matches []string
+	for _, name := range append(universum.Commands(), shellCommands...) {
+		if strings.HasPrefix(name, strings.ToUpper(prefix)) {
+			matches = append(matches, name)
+		}
+	}
+
+	sort.Strings(matches)
+	return matches
+}
+
+// runCommand runs a tokenized command line
+func runCommand(ctx context.Context, client *universum.Client, words []token, out io.Writer) error {
+	name := strings.ToUpper(words[0].text)
+
+	cmd, ok := commands[name]
+	if !ok {
+		return fmt.Errorf("unknown command %q, expected one of %s: %w",
+			words[0].text, strings.Join(universum.Commands(), ", "), errSyntax)
+	}
+
+	args := words[1:]
+	if len(args) < cmd.minArgs || (cmd.maxArgs >= 0 && len(args) > cmd.maxArgs) {
+		return fmt.Errorf("usage: %s: %w", strings.TrimSpace(name+" "+cmd.usage), errSyntax)
+	}
+
+	return cmd.run(ctx, client, args, out)
+}
+
+// tokenize splits a command line into words. Words are separated by spaces
+// and may be quoted with double quotes, which support the escapes \n, \r,
+// \t, \" and \\, or single quotes, which take everything literally.
+func tokenize(line string) ([]token, error) {
+	var words []token
+	var current strings.Builder
+	inWord, quoted := false, false
+	var quote rune
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case quote == '"' && r == '\\':
+			if i+1 == len(runes) {
+				return nil, fmt.Errorf("unterminated escape at the end of the line: %w", errSyntax)
+			}
+			i++
+
+			switch runes[i] {
+			case 'n':
+				current.WriteRune('\n')
+			case 'r':
+				current.WriteRune('\r')
+			case 't':
+				current.WriteRune('\t')
+			default:
+				current.WriteRune(runes[i])
+			}
+
+		case quote != 0 && r == quote:
+			quote = 0
+
+		case quote != 0:
+			current.WriteRune(r)
+
+		case r == '"' || r == '\'':
+			quote, inWord, quoted = r, true, true
+
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, token{text: current.String(), quoted: quoted})
+				current.Reset()
+				inWord, quoted = false, false
+			}
+
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote: %w", quote, errSyntax)
+	}
+
+	if inWord {
+		words = append(words, token{text: current.String(), quoted: quoted})
+	}
+
+	return words, nil
+}
+
+// parseValue converts an unquoted word into an integer, float or boolean if
+// it looks like one, and keeps everything else as a string
+func parseValue(word token) interface{} {
+	if word.quoted {
+		return word.text
+	}
+
+	if i, err := strconv.ParseInt(word.text, 10, 64); err == nil {
+		return i
+	}
+
+	if f, err := strconv.ParseFloat(word.text, 64); err == nil {
+		return f
+	}
+
+	if word.text == "true" || word.text == "false" {
+		return word.text == "true"
+	}
+
+	return word.text
+}
+
+func parseInt(name string, word token) (int64, error) {
+	value, err := strconv.ParseInt(word.text, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer, got %q: %w", name, word.text, errSyntax)
+	}
+
+	return value, nil
+}
+
+// optionalOffset returns the offset of INCR and DECR, which defaults to 1
+func optionalOffset(args []token) (int64, error) {
+	if len(args) < 2 {
+		return 1, nil
+	}
+
+	return parseInt("offset", args[1])
+}
+
+func texts(words []token) []string {
+	texts := make([]string, len(words))
+	for i, word := range words {
+		texts[i] = word.text
+	}
+
+	return texts
+}
+
+// printResult writes the formatted value, followed by the name of the response code
+func printResult(out io.Writer, code int64, value string) error {
+	_, err := fmt.Fprintf(out, "%s\n(%s)\n", value, universum.RespCodeName(code))
+	return err
+}
+
+func quote(s string) string {
+	return strconv.Quote(s)
+}
+
+// formatValue formats a value, quoting strings so that they are told apart from numbers
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "(nil)"
+	case string:
+		return quote(v)
+	case int64:
+		return "(integer) " + strconv.FormatInt(v, 10)
+	case float64:
+		return "(float) " + strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return "(boolean) " + strconv.FormatBool(v)
+	case []interface{}:
+		if len(v) == 0 {
+			return "(empty list)"
+		}
+
+		lines := make([]string, len(v))
+		for i, item := range v {
+			lines[i] = strconv.Itoa(i+1) + ") " + formatValue(item)
+		}
+		return strings.Join(lines, "\n")
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// formatMap formats formatted values by key, in the order of the keys
+func formatMap(values map[string]string) string {
+	if len(values) == 0 {
+		return "(empty map)"
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, len(keys))
+	for i, key := range keys {
+		lines[i] = strconv.Itoa(i+1) + ") " + quote(key) + " => " + values[key]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func formatBools(values map[string]bool) string {
+	formatted := make(map[string]string, len(values))
+	for key, value := range values {
+		formatted[key] = formatValue(value)
+	}
+
+	return formatMap(formatted)
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxHistory is the number of lines kept in the history
+const maxHistory = 1000
+
+// errInterrupted is returned by readLine when the line is abandoned with Ctrl-C
+var errInterrupted = errors.New("interrupted")
+
+// Key codes of the line editor
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlH     = 8
+	keyTab       = 9
+	keyLineFeed  = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyEnter     = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyBackspace = 127
+)
+
+// lineEditor reads lines from a terminal in raw mode, with cursor movement,
+// history and tab completion of the first word of the line.
+type lineEditor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	complete func(prefix string) []string
+
+	history     []string
+	historyFile string
+
+	// the line being edited and the position of the cursor in it
+	line   []rune
+	cursor int
+}
+
+func newLineEditor(in io.Reader, out io.Writer, complete func(prefix string) []string) *lineEditor {
+	return &lineEditor{in: bufio.NewReader(in), out: out, complete: complete}
+}
+
+// loadHistory reads the history from the file and appends the lines entered from now on to it
+func (e *lineEditor) loadHistory(path string) error {
+	e.historyFile = path
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		if line != "" {
+			e.history = append(e.history, line)
+		}
+	}
+
+	if len(e.history) > maxHistory {
+		e.history = e.history[len(e.history)-maxHistory:]
+	}
+
+	return nil
+}
+
+// addHistory appends a line to the history unless it repeats the previous one
+func (e *lineEditor) addHistory(line string) {
+	if line == "" || (len(e.history) > 0 && e.history[len(e.history)-1] == line) {
+		return
+	}
+
+	e.history = append(e.history, line)
+	if len(e.history) > maxHistory {
+		e.history = e.history[1:]
+	}
+
+	if e.historyFile == "" {
+		return
+	}
+
+	// the history is best effort, a read-only home directory does not stop the shell
+	if file, err := os.OpenFile(e.historyFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600); err == nil {
+		fmt.Fprintln(file, line)
+		file.Close()
+	}
+}
+
+// readLine reads a line, showing the prompt. It returns io.EOF on Ctrl-D
+// at an empty line, and errInterrupted on Ctrl-C.
+func (e *lineEditor) readLine(prompt string) (string, error) {
+	e.line = e.line[:0]
+	e.cursor = 0
+
+	// the entry past the end of the history is the line being entered
+	historyPos := len(e.history)
+	var pending string
+
+	e.refresh(prompt)
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			if err == io.EOF && len(e.line) > 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return string(e.line), nil
+			}
+			return "", err
+		}
+
+		switch r {
+		case keyEnter, keyLineFeed:
+			fmt.Fprint(e.out, "\r\n")
+			return string(e.line), nil
+
+		case keyCtrlC:
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", errInterrupted
+
+		case keyCtrlD:
+			if len(e.line) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.deleteAt(e.cursor)
+
+		case keyBackspace, keyCtrlH:
+			if e.cursor > 0 {
+				e.cursor--
+				e.deleteAt(e.cursor)
+			}
+
+		case keyCtrlA:
+			e.cursor = 0
+
+		case keyCtrlE:
+			e.cursor = len(e.line)
+
+		case keyCtrlB:
+			e.cursor = max(e.cursor-1, 0)
+
+		case keyCtrlF:
+			e.cursor = min(e.cursor+1, len(e.line))
+
+		case keyCtrlK:
+			e.line = e.line[:e.cursor]
+
+		case keyCtrlU:
+			e.line = append(e.line[:0], e.line[e.cursor:]...)
+			e.cursor = 0
+
+		case keyCtrlW:
+			start := e.cursor
+			for start > 0 && e.line[start-1] == ' ' {
+				start--
+			}
+			for start > 0 && e.line[start-1] != ' ' {
+				start--
+			}
+			e.line = append(e.line[:start], e.line[e.cursor:]...)
+			e.cursor = start
+
+		case keyCtrlL:
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+
+		case keyCtrlP, keyCtrlN:
+			historyPos, pending = e.browseHistory(historyPos, pending, r == keyCtrlP)
+
+		case keyTab:
+			e.completeWord(prompt)
+
+		case keyEscape:
+			historyPos, pending = e.escapeSequence(historyPos, pending)
+
+		default:
+			if r >= ' ' && r != utf8.RuneError {
+				e.line = append(e.line[:e.cursor], append([]rune{r}, e.line[e.cursor:]...)...)
+				e.cursor++
+			}
+		}
+
+		e.refresh(prompt)
+	}
+}
+
+// escapeSequence handles the arrow, Home, End and Delete keys
+func (e *lineEditor) escapeSequence(historyPos int, pending string) (int, string) {
+	first, err := e.in.ReadByte()
+	if err != nil || (first != '[' && first != 'O') {
+		return historyPos, pending
+	}
+
+	key, err := e.in.ReadByte()
+	if err != nil {
+		return historyPos, pending
+	}
+
+	// sequences like ESC [ 3 ~ end with a tilde after the number
+	if key >= '0' && key <= '9' {
+		if tilde, err := e.in.ReadByte(); err != nil || tilde != '~' {
+			return historyPos, pending
+		}
+	}
+
+	switch key {
+	case 'A':
+		return e.browseHistory(historyPos, pending, true)
+	case 'B':
+		return e.browseHistory(historyPos, pending, false)
+	case 'C':
+		e.cursor = min(e.cursor+1, len(e.line))
+	case 'D':
+		e.cursor = max(e.cursor-1, 0)
+	case 'H', '1', '7':
+		e.cursor = 0
+	case 'F', '4', '8':
+		e.cursor = len(e.line)
+	case '3':
+		if e.cursor < len(e.line) {
+			e.deleteAt(e.cursor)
+		}
+	}
+
+	return historyPos, pending
+}
+
+// browseHistory replaces the line with the previous or next line of the
+// history, keeping the line being entered to return to it
+func (e *lineEditor) browseHistory(historyPos int, pending string, previous bool) (int, string) {
+	if historyPos == len(e.history) {
+		pending = string(e.line)
+	}
+
+	switch {
+	case previous && historyPos > 0:
+		historyPos--
+	case !previous && historyPos < len(e.history):
+		historyPos++
+	default:
+		return historyPos, pending
+	}
+
+	if historyPos == len(e.history) {
+		e.line = []rune(pending)
+	} else {
+		e.line = []rune(e.history[historyPos])
+	}
+	e.cursor = len(e.line)
+
+	return historyPos, pending
+}
+
+// completeWord completes the command at the start of the line. A single match
+// is completed with a trailing space, several matches are completed up to
+// their common prefix, or listed if there is none.
+func (e *lineEditor) completeWord(prompt string) {
+	word := string(e.line[:e.cursor])
+	if strings.ContainsRune(word, ' ') || e.complete == nil {
+		return
+	}
+
+	matches := e.complete(word)
+	switch {
+	case len(matches) == 0:
+		return
+
+	case len(matches) == 1:
+		e.replaceWord(matches[0] + " ")
+
+	default:
+		prefix := commonPrefix(matches)
+		if len(prefix) > len(word) {
+			e.replaceWord(prefix)
+			return
+		}
+
+		fmt.Fprint(e.out, "\r\n"+strings.Join(matches, "  ")+"\r\n")
+	}
+}
+
+// replaceWord replaces the text before the cursor
+func (e *lineEditor) replaceWord(text string) {
+	e.line = append([]rune(text), e.line[e.cursor:]...)
+	e.cursor = utf8.RuneCountInString(text)
+}
+
+func (e *lineEditor) deleteAt(pos int) {
+	if pos < len(e.line) {
+		e.line = append(e.line[:pos], e.line[pos+1:]...)
+	}
+}
+
+// refresh redraws the prompt and the line, and moves the cursor into place
+func (e *lineEditor) refresh(prompt string) {
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K\r", prompt, string(e.line))
+
+	if column := utf8.RuneCountInString(prompt) + e.cursor; column > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dC", column)
+	}
+}
+
+// commonPrefix returns the longest prefix shared by all words
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	return prefix
+}
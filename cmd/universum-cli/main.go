@@ -0,0 +1,258 @@
+// Command universum-cli is a command line client for Universum DB. It runs the
+// command given as arguments, or else an interactive shell with history and
+// Tab completion of the command names:
+//
+//	universum-cli -url universum://localhost:11191 SET greeting hello
+//	universum-cli --readonly
+//
+// Every result is followed by the name of its response code, e.g.
+// (RespRecordFound). Commands piped into the standard input are run line by
+// line, without prompt.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cshekharsharma/universum-client-go"
+)
+
+// shellCommands are the commands of the shell itself, next to the server commands
+var shellCommands = []string{"EXIT", "QUIT"}
+
+// config holds the command line flags
+type config struct {
+	url         string
+	username    string
+	password    string
+	token       string
+	tls         bool
+	caFile      string
+	certFile    string
+	keyFile     string
+	serverName  string
+	insecure    bool
+	readonly    bool
+	historyFile string
+	args        []string
+}
+
+func main() {
+	cfg, err := parseFlags(os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	os.Exit(run(cfg, os.Stdin, os.Stdout, os.Stderr))
+}
+
+// parseFlags parses the command line flags into a config
+func parseFlags(args []string, output io.Writer) (*config, error) {
+	cfg := &config{}
+
+	var history string
+	if home, err := os.UserHomeDir(); err == nil {
+		history = filepath.Join(home, ".universum_cli_history")
+	}
+
+	flags := flag.NewFlagSet("universum-cli", flag.ContinueOnError)
+	flags.SetOutput(output)
+	flags.Usage = func() {
+		fmt.Fprintln(output, "Usage: universum-cli [flags] [command [arguments...]]")
+		flags.PrintDefaults()
+	}
+
+	flags.StringVar(&cfg.url, "url", "universum://localhost:11191", "connection URL of the server, see universum.ParseURL")
+	flags.StringVar(&cfg.username, "user", "", "username to authenticate with")
+	flags.StringVar(&cfg.password, "pass", "", "password to authenticate with")
+	flags.StringVar(&cfg.token, "token", "", "token to authenticate with")
+	flags.BoolVar(&cfg.tls, "tls", false, "connect over TLS, also enabled by the universums URL scheme")
+	flags.StringVar(&cfg.caFile, "cacert", "", "CA certificate file verifying the server")
+	flags.StringVar(&cfg.certFile, "cert", "", "client certificate file for mutual TLS")
+	flags.StringVar(&cfg.keyFile, "key", "", "client private key file for mutual TLS")
+	flags.StringVar(&cfg.serverName, "sni", "", "server name to verify the server certificate against")
+	flags.BoolVar(&cfg.insecure, "insecure", false, "skip the verification of the server certificate")
+	flags.BoolVar(&cfg.readonly, "readonly", false, "reject write commands without sending them")
+	flags.StringVar(&cfg.historyFile, "history", history, "file keeping the history of the shell, empty to disable")
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg.args = flags.Args()
+	return cfg, nil
+}
+
+// newOptions builds the client options from the URL, overridden by the flags
+func newOptions(cfg *config) (*universum.Options, error) {
+	opts, err := universum.ParseURL(cfg.url)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.username != "" || cfg.password != "" {
+		opts.Username, opts.Password = cfg.username, cfg.password
+	}
+	if cfg.token != "" {
+		opts.Token = cfg.token
+	}
+
+	opts.EnableTLS = opts.EnableTLS || cfg.tls || cfg.caFile != "" || cfg.certFile != ""
+	if cfg.caFile != "" {
+		opts.CAFile = cfg.caFile
+	}
+	if cfg.certFile != "" || cfg.keyFile != "" {
+		opts.TLSCertFile, opts.TLSKeyFile = cfg.certFile, cfg.keyFile
+	}
+	if cfg.serverName != "" {
+		opts.TLSServerName = cfg.serverName
+	}
+
+	opts.InsecureSkipVerify = opts.InsecureSkipVerify || cfg.insecure
+	opts.IsReadonly = opts.IsReadonly || cfg.readonly
+
+	// a single connection serves the shell
+	opts.ConnPoolsize = 1
+
+	return opts, opts.Validate()
+}
+
+// run runs the command of the arguments, or the shell, and returns the exit code
+func run(cfg *config, in io.Reader, out, errOut io.Writer) int {
+	opts, err := newOptions(cfg)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 2
+	}
+
+	client, err := universum.NewClient(opts)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	defer client.Close()
+
+	if len(cfg.args) > 0 {
+		words := make([]token, len(cfg.args))
+		for i, arg := range cfg.args {
+			words[i] = token{text: arg}
+		}
+
+		if err := runCommand(context.Background(), client, words, out); err != nil {
+			fmt.Fprintf(errOut, "(error) %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	if file, ok := in.(*os.File); ok && isTerminal(int(file.Fd())) {
+		return runShell(cfg, opts, client, file, out, errOut)
+	}
+
+	return runScript(client, in, out, errOut)
+}
+
+// execute runs a line of the shell, reporting whether it succeeded and whether the shell must exit
+func execute(client *universum.Client, line string, out, errOut io.Writer) (ok bool, exit bool) {
+	words, err := tokenize(line)
+	if err != nil {
+		fmt.Fprintf(errOut, "(error) %v\n", err)
+		return false, false
+	}
+
+	if len(words) == 0 {
+		return true, false
+	}
+
+	switch strings.ToUpper(words[0].text) {
+	case "EXIT", "QUIT":
+		return true, true
+	}
+
+	if err := runCommand(context.Background(), client, words, out); err != nil {
+		fmt.Fprintf(errOut, "(error) %v\n", err)
+		return false, false
+	}
+
+	return true, false
+}
+
+// runShell runs the interactive shell on a terminal
+func runShell(cfg *config, opts *universum.Options, client *universum.Client, terminal *os.File, out, errOut io.Writer) int {
+	editor := newLineEditor(terminal, out, completions)
+	if cfg.historyFile != "" {
+		if err := editor.loadHistory(cfg.historyFile); err != nil {
+			fmt.Fprintf(errOut, "failed to load the history: %v\n", err)
+		}
+	}
+
+	prompt := opts.HostAddr + "> "
+	if opts.IsReadonly {
+		prompt = opts.HostAddr + " (readonly)> "
+	}
+
+	fd := int(terminal.Fd())
+	for {
+		// the terminal is raw only while editing, so that results print as usual
+		state, err := makeRaw(fd)
+		if err != nil {
+			fmt.Fprintf(errOut, "failed to configure the terminal: %v\n", err)
+			return 1
+		}
+
+		line, err := editor.readLine(prompt)
+		restore(fd, state)
+
+		switch {
+		case errors.Is(err, errInterrupted):
+			continue
+		case errors.Is(err, io.EOF):
+			return 0
+		case err != nil:
+			fmt.Fprintln(errOut, err)
+			return 1
+		}
+
+		editor.addHistory(strings.TrimSpace(line))
+
+		if _, exit := execute(client, line, out, errOut); exit {
+			return 0
+		}
+	}
+}
+
+// runScript runs the commands read from a pipe or file, one per line, and
+// fails if any of them failed
+func runScript(client *universum.Client, in io.Reader, out, errOut io.Writer) int {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	code := 0
+
+	for scanner.Scan() {
+		ok, exit := execute(client, scanner.Text(), out, errOut)
+		if !ok {
+			code = 1
+		}
+		if exit {
+			break
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+
+	return code
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cshekharsharma/universum-client-go"
+	"github.com/cshekharsharma/universum-client-go/universumtest"
+)
+
+func TestTokenize(t *testing.T) {
+	words, err := tokenize(`SET  greeting "hello \"world\"\n" 'it''s' 42`)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []token{
+		{text: "SET"},
+		{text: "greeting"},
+		{text: "hello \"world\"\n", quoted: true},
+		{text: "its", quoted: true},
+		{text: "42"},
+	}
+	if !reflect.DeepEqual(words, expected) {
+		t.Fatalf("Expected %v, got %v", expected, words)
+	}
+
+	for _, line := range []string{`GET "open`, `GET 'open`, `GET "trailing\`} {
+		if _, err := tokenize(line); err == nil {
+			t.Fatalf("Expected an error for %s", line)
+		}
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	for _, tc := range []struct {
+		word     token
+		expected interface{}
+	}{
+		{token{text: "42"}, int64(42)},
+		{token{text: "4.5"}, 4.5},
+		{token{text: "true"}, true},
+		{token{text: "hello"}, "hello"},
+		{token{text: "42", quoted: true}, "42"},
+		{token{text: "true", quoted: true}, "true"},
+	} {
+		if value := parseValue(tc.word); value != tc.expected {
+			t.Fatalf("Expected %#v for %v, got %#v", tc.expected, tc.word, value)
+		}
+	}
+}
+
+func TestCompletions(t *testing.T) {
+	if matches := completions("ge"); !reflect.DeepEqual(matches, []string{"GET"}) {
+		t.Fatalf("Expected GET, got %v", matches)
+	}
+
+	if matches := completions("M"); !reflect.DeepEqual(matches, []string{"MDELETE", "MGET", "MSET"}) {
+		t.Fatalf("Expected the multi-key commands, got %v", matches)
+	}
+
+	for _, name := range universum.Commands() {
+		if _, ok := commands[name]; !ok {
+			t.Fatalf("Expected a shell command for %s", name)
+		}
+	}
+}
+
+func TestLineEditor(t *testing.T) {
+	// "ge" Tab "k" Left Left "x" Enter, then Up Enter, then Ctrl-C, then Ctrl-D
+	input := "ge\tk\x1b[D\x1b[Dx\r" + "\x1b[A\r" + "abc\x03" + "\x04"
+
+	var out bytes.Buffer
+	editor := newLineEditor(strings.NewReader(input), &out, completions)
+
+	line, err := editor.readLine("> ")
+	if err != nil || line != "GETx k" {
+		t.Fatalf("Expected the completed line \"GETx k\", got %q and %v", line, err)
+	}
+	editor.addHistory(line)
+
+	if line, err = editor.readLine("> "); err != nil || line != "GETx k" {
+		t.Fatalf("Expected the line from the history, got %q and %v", line, err)
+	}
+
+	if _, err = editor.readLine("> "); err != errInterrupted {
+		t.Fatalf("Expected errInterrupted, got %v", err)
+	}
+
+	if _, err = editor.readLine("> "); err != io.EOF {
+		t.Fatalf("Expected io.EOF, got %v", err)
+	}
+}
+
+func TestLineEditor_History(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	editor := newLineEditor(strings.NewReader(""), io.Discard, nil)
+	if err := editor.loadHistory(path); err != nil {
+		t.Fatalf("Expected no error for a missing history, got %v", err)
+	}
+
+	editor.addHistory("GET a")
+	editor.addHistory("GET a")
+	editor.addHistory("GET b")
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if string(content) != "GET a\nGET b\n" {
+		t.Fatalf("Expected the history without repeated lines, got %q", content)
+	}
+
+	reloaded := newLineEditor(strings.NewReader(""), io.Discard, nil)
+	if err := reloaded.loadHistory(path); err != nil || len(reloaded.history) != 2 {
+		t.Fatalf("Expected 2 lines of history, got %v and %v", reloaded.history, err)
+	}
+}
+
+// runCLI runs the command line client against the server with the given flags and input
+func runCLI(t *testing.T, srv *universumtest.Server, input string, args ...string) (int, string, string) {
+	t.Helper()
+
+	cfg, err := parseFlags(append([]string{"-url", "universum://" + srv.Addr(), "-history", ""}, args...), io.Discard)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var out, errOut bytes.Buffer
+	code := run(cfg, strings.NewReader(input), &out, &errOut)
+
+	return code, out.String(), errOut.String()
+}
+
+func TestRun_OneShot(t *testing.T) {
+	srv := universumtest.NewServer(t)
+
+	if code, out, errOut := runCLI(t, srv, "", "SET", "greeting", "hello world"); code != 0 || out != "(boolean) true\n(RespRecordUpdated)\n" {
+		t.Fatalf("Expected a successful SET, got %d, %q and %q", code, out, errOut)
+	}
+
+	if code, out, _ := runCLI(t, srv, "", "get", "greeting"); code != 0 || out != "\"hello world\"\n(RespRecordFound)\n" {
+		t.Fatalf("Expected the value with its response code, got %d and %q", code, out)
+	}
+
+	if code, out, _ := runCLI(t, srv, "", "GET", "missing"); code != 0 || out != "(nil)\n(RespRecordNotFound)\n" {
+		t.Fatalf("Expected nil for a missing key, got %d and %q", code, out)
+	}
+
+	if code, _, errOut := runCLI(t, srv, "", "GET"); code != 1 || !strings.Contains(errOut, "usage: GET key") {
+		t.Fatalf("Expected the usage of GET, got %d and %q", code, errOut)
+	}
+
+	if code, _, errOut := runCLI(t, srv, "", "-readonly", "SET", "greeting", "bye"); code != 1 || !strings.Contains(errOut, universum.ErrClientReadonly.Error()) {
+		t.Fatalf("Expected the readonly client to reject SET, got %d and %q", code, errOut)
+	}
+}
+
+func TestRun_Script(t *testing.T) {
+	srv := universumtest.NewServer(t)
+
+	script := strings.Join([]string{
+		"MSET a 1 b 'two'",
+		"INCR a 41",
+		"MGET a b c",
+		"NOPE",
+		"quit",
+		"PING",
+	}, "\n")
+
+	code, out, errOut := runCLI(t, srv, script)
+	if code != 1 {
+		t.Fatalf("Expected the failed command to fail the script, got %d", code)
+	}
+
+	expected := strings.Join([]string{
+		`1) "a" => (boolean) true`,
+		`2) "b" => (boolean) true`,
+		`(RespMsetCompleted)`,
+		`(integer) 42`,
+		`(RespRecordUpdated)`,
+		`1) "a" => (integer) 42 (RespRecordFound)`,
+		`2) "b" => "two" (RespRecordFound)`,
+		`3) "c" => (nil) (RespRecordNotFound)`,
+		`(RespMgetCompleted)`,
+	}, "\n") + "\n"
+
+	if out != expected {
+		t.Fatalf("Expected output\n%s\ngot\n%s", expected, out)
+	}
+
+	if !strings.Contains(errOut, `unknown command "NOPE"`) {
+		t.Fatalf("Expected an error for the unknown command, got %q", errOut)
+	}
+}
@@ -0,0 +1,27 @@
+package universum
+
+import "context"
+
+// Cmdable is the set of commands supported by the Universum database. It is
+// implemented by Client, and code depending on Cmdable instead of *Client can
+// be unit-tested with a fake such as universummock.Mock.
+type Cmdable interface {
+	Get(ctx context.Context, key string) (*GetResult, error)
+	Set(ctx context.Context, key string, value interface{}, ttl int64) (*SetResult, error)
+	Exists(ctx context.Context, key string) (*ExistsResult, error)
+	Delete(ctx context.Context, key string) (*DeleteResult, error)
+	Increment(ctx context.Context, key string, offset int64) (*IncrementResult, error)
+	Decrement(ctx context.Context, key string, offset int64) (*DecrementResult, error)
+	Append(ctx context.Context, key string, value string) (*AppendResult, error)
+	MGet(ctx context.Context, keys []string) (*MGetResult, error)
+	MSet(ctx context.Context, kv map[string]interface{}) (*MSetResult, error)
+	MDelete(ctx context.Context, keys []string) (*MDeleteResult, error)
+	TTL(ctx context.Context, key string) (*TTLResult, error)
+	Expire(ctx context.Context, key string, ttl int64) (*ExpireResult, error)
+	Ping(ctx context.Context) (*PingResult, error)
+	Info(ctx context.Context) (*InfoResult, error)
+	Help(ctx context.Context) (*HelpResult, error)
+	Snapshot(ctx context.Context) (*SnapshotResult, error)
+}
+
+var _ Cmdable = (*Client)(nil)
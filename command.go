@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"time"
@@ -26,18 +27,128 @@ const (
 	commandSnapshot string = "SNAPSHOT"
 	commandInfo     string = "INFO"
 	commandHelp     string = "HELP"
+	commandAuth     string = "AUTH"
+	commandHello    string = "HELLO"
 )
 
 const remoteByteDelimiter = "\x04\x04\x04\x04"
 
+// Commands returns the names of the commands the client sends on behalf of
+// its callers, e.g. for completion in interactive tools. The connection setup
+// commands AUTH and HELLO are left out.
+func Commands() []string {
+	return []string{commandPing, commandExists, commandGet, commandSet, commandDelete, commandIncr,
+		commandDecr, commandAppend, commandMget, commandMset, commandMdelete, commandTtl, commandExpire,
+		commandSnapshot, commandInfo, commandHelp}
+}
+
 func sendCommand(ctx context.Context, c *Client, command string, args ...interface{}) (*CommandResult, error) {
+	trace := newCommandTrace()
+
+	if c.breaker != nil {
+		if err := c.breaker.allow(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.limiter != nil {
+		done, err := c.limiter.acquire(ctx, command)
+		if err != nil {
+			return nil, err
+		}
+		defer done()
+	}
+
+	if c.backpressure != nil {
+		if err := c.backpressure.acquire(ctx); err != nil {
+			return nil, err
+		}
+		defer c.backpressure.release()
+	}
+
+	trace.limiterWait = time.Since(trace.start)
+	result, err := executeWithBusyRetries(ctx, c, trace, command, args...)
+
+	if c.breaker != nil {
+		c.breaker.record(err)
+	}
+
+	if c.slowlog != nil {
+		c.slowlog.observe(trace, command, args, err)
+	}
+
+	return result, err
+}
+
+// executeWithBusyRetries sends the command, backing off and retrying it when
+// adaptive backpressure is enabled, the server answers RespServerBusy and the
+// command is idempotent. Without adaptive backpressure, busy replies are
+// returned like any other result.
+func executeWithBusyRetries(ctx context.Context, c *Client, trace *commandTrace, command string, args ...interface{}) (*CommandResult, error) {
+	for attempt := int64(0); ; attempt++ {
+		result, err := executeCommand(ctx, c, trace, command, args...)
+		if err != nil || c.backpressure == nil {
+			return result, err
+		}
+
+		if result.code != RespServerBusy {
+			c.backpressure.onSuccess()
+			return result, nil
+		}
+
+		// every busy reply lowers the allowed concurrency, including the last one
+		c.backpressure.onBusy()
+
+		if !isIdempotentCommand(command) || attempt >= c.opts.MaxRetries {
+			return nil, fmt.Errorf("server is busy, %s not completed after %d attempts: %w",
+				command, attempt+1, ErrServerBusy)
+		}
+
+		if err := sleepContext(ctx, busyBackoff(c.opts, attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func executeCommand(ctx context.Context, c *Client, trace *commandTrace, command string, args ...interface{}) (*CommandResult, error) {
+	poolStart := time.Now()
 	conn, err := c.pool.GetConn(ctx)
+	trace.poolWait = time.Since(poolStart)
 	if err != nil {
 		return nil, err
 	}
 
-	defer c.pool.ReleaseConn(ctx, conn)
+	result, err := execOnConn(ctx, conn, c.opts, trace, command, args...)
 
+	if err == nil && result.code == RespAuthRequired && c.pool.auth != nil {
+		if authErr := c.pool.auth.reauthenticate(ctx, conn); authErr != nil {
+			c.pool.Remove(ctx, conn)
+			return nil, authErr
+		}
+		result, err = execOnConn(ctx, conn, c.opts, trace, command, args...)
+	}
+
+	if isConnBroken(err) {
+		c.pool.Remove(ctx, conn)
+		return nil, err
+	}
+
+	if err == nil && result.code == RespServerShuttingDown {
+		c.pool.Remove(ctx, conn)
+		c.pool.markUnhealthy(c.opts.ShutdownRedialDelay)
+		return nil, fmt.Errorf("server is shutting down, redialing after %s: %w",
+			c.opts.ShutdownRedialDelay, ErrServerShuttingDown)
+	}
+
+	c.pool.ReleaseConn(ctx, conn)
+	return result, err
+}
+
+// execOnConn performs a single request/response round trip of the command on
+// the given connection, recording the time spent in each phase into trace.
+// Socket I/O is bound by the context deadline as well as the configured
+// timeouts, and cancelling the context interrupts a blocked write or read.
+func execOnConn(ctx context.Context, conn connInterface, opts *Options, trace *commandTrace, command string, args ...interface{}) (*CommandResult, error) {
 	cmdInput := make([]interface{}, 0, len(args)+1)
 	cmdInput = append(cmdInput, command)
 	cmdInput = append(cmdInput, args...)
@@ -47,16 +158,25 @@ func sendCommand(ctx context.Context, c *Client, command string, args ...interfa
 		return nil, fmt.Errorf("resp encoding failed before sending the command: %w", ErrCommandEncodingFailed)
 	}
 
-	if c.opts.WriteTimeout > 0 {
-		err := conn.getNetConn().SetWriteDeadline(time.Now().Add(c.opts.WriteTimeout))
-		if err != nil {
-			return nil, fmt.Errorf("failed to set write deadline: %v", err)
-		}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	stopInterrupt := context.AfterFunc(ctx, func() {
+		_ = conn.getNetConn().SetDeadline(aLongTimeAgo)
+	})
+	defer stopInterrupt()
+
+	phaseStart := time.Now()
+
+	err = conn.getNetConn().SetWriteDeadline(conn.deadline(ctx, opts.WriteTimeout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to set write deadline [%v]: %w", err, ErrConnectionConfigFailed)
 	}
 
 	bytesWritten, err := conn.write([]byte(encodedCommand))
 	if err != nil {
-		return nil, fmt.Errorf("failed while writing bytes to the socket: %w", ErrSocketWriteFailed)
+		return nil, withContextErr(ctx, fmt.Errorf("failed while writing bytes to the socket: %w", ErrSocketWriteFailed))
 	}
 	if bytesWritten != len(encodedCommand) {
 		return nil, fmt.Errorf("incomplete write: wrote %d/%d bytes: %w",
@@ -64,15 +184,39 @@ func sendCommand(ctx context.Context, c *Client, command string, args ...interfa
 	}
 
 	if err := conn.getWriter().Flush(); err != nil {
-		return nil, fmt.Errorf("failed to flush writer: %w", ErrSocketFlushFailed)
+		return nil, withContextErr(ctx, fmt.Errorf("failed to flush writer: %w", ErrSocketFlushFailed))
 	}
 
-	decodedBuffer, err := readUntilDelimiter(conn, c.opts, remoteByteDelimiter)
+	trace.write = time.Since(phaseStart)
+	phaseStart = time.Now()
+
+	decodedBuffer, err := readUntilDelimiter(ctx, conn, opts, remoteByteDelimiter)
+	trace.read = time.Since(phaseStart)
 	if err != nil {
-		return nil, fmt.Errorf("failed while reading bytes from the socket: [%v] %w", err, ErrSocketReadFailed)
+		return nil, withContextErr(ctx, fmt.Errorf("failed while reading bytes from the socket: [%w] %w", err, ErrSocketReadFailed))
 	}
 
-	decoded, err := decodeResp(bufio.NewReader(decodedBuffer))
+	phaseStart = time.Now()
+	defer func() {
+		trace.decode = time.Since(phaseStart)
+	}()
+
+	return parseReply(decodedBuffer.Bytes(), opts)
+}
+
+// parseReply validates and decodes a reply read up to the delimiter into its
+// value, code and message. Replies are checked before decoding, so that
+// hostile input fails with an error instead of exhausting the decoder.
+func parseReply(data []byte, opts *Options) (*CommandResult, error) {
+	if err := validateResp(data, opts.MaxReplyDepth); err != nil {
+		if err == errIncompleteResp {
+			return nil, fmt.Errorf("truncated reply of %d bytes: %w", len(data), ErrMalformedResponseReceived)
+		}
+		return nil, err
+	}
+
+	// the decoder reads lines and bulk strings with single reads, so the buffer must hold the whole reply
+	decoded, err := decodeResp(bufio.NewReaderSize(bytes.NewReader(data), len(data)))
 	if _, ok := decoded.(error); ok {
 		return nil, fmt.Errorf("server rejected the request: %v : %w", decoded, ErrServerRejectedRequest)
 	}
@@ -84,20 +228,43 @@ func sendCommand(ctx context.Context, c *Client, command string, args ...interfa
 	return toCommandResult(decoded)
 }
 
-func readUntilDelimiter(conn connInterface, opts *Options, delim string) (*bytes.Buffer, error) {
+// withContextErr attaches the context error to err when the context is done,
+// so that callers can tell an abandoned command from a network failure. A socket
+// deadline derived from the context may fire just before the context's own timer,
+// so a passed context deadline counts as exceeded as well.
+func withContextErr(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%w: %w", err, ctxErr)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+		return fmt.Errorf("%w: %w", err, context.DeadlineExceeded)
+	}
+	return err
+}
+
+// isConnBroken reports whether err left the connection in an unknown state,
+// e.g. with a partially written request or an unread reply, so that it must
+// not be reused.
+func isConnBroken(err error) bool {
+	return errors.Is(err, ErrConnectionConfigFailed) ||
+		errors.Is(err, ErrSocketWriteFailed) ||
+		errors.Is(err, ErrIncompleteSocketWrite) ||
+		errors.Is(err, ErrSocketFlushFailed) ||
+		errors.Is(err, ErrSocketReadFailed)
+}
+
+func readUntilDelimiter(ctx context.Context, conn connInterface, opts *Options, delim string) (*bytes.Buffer, error) {
 	delimiterBytes := []byte(delim)
 	delimiterLen := len(delimiterBytes)
 
-	if opts.ReadTimeout > 0 {
-		err := conn.getNetConn().SetReadDeadline(time.Now().Add(opts.ReadTimeout))
-		if err != nil {
-			return nil, fmt.Errorf("failed to set read deadline: %v", err)
-		}
+	err := conn.getNetConn().SetReadDeadline(conn.deadline(ctx, opts.ReadTimeout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to set read deadline: %w", err)
 	}
 
 	reader := conn.getReader()
 	var buffer bytes.Buffer
-	var decoderBuffer bytes.Buffer
 	pipe := make([]byte, 1024) // Read in chunks
 
 	for {
@@ -107,17 +274,25 @@ func readUntilDelimiter(conn connInterface, opts *Options, delim string) (*bytes
 				// If EOF is received but some data is present, return what we have.
 				break
 			}
-			return nil, fmt.Errorf("error reading from connection: %v", err)
+			return nil, fmt.Errorf("error reading from connection: %w", err)
 		}
 
 		buffer.Write(pipe[:chunkSize])
-		decoderBuffer.Write(pipe[:chunkSize])
+
+		if opts.MaxReplySize > 0 && int64(buffer.Len()) > opts.MaxReplySize+int64(delimiterLen) {
+			return nil, fmt.Errorf("reply exceeds %d bytes: %w", opts.MaxReplySize, ErrReplyTooLarge)
+		}
 
 		if buffer.Len() >= delimiterLen && bytes.HasSuffix(buffer.Bytes(), delimiterBytes) {
-			decoderBuffer.Truncate(decoderBuffer.Len() - delimiterLen)
+			// a value containing the delimiter may end a chunk, in which case the reply goes on
+			if validateResp(buffer.Bytes()[:buffer.Len()-delimiterLen], opts.MaxReplyDepth) == errIncompleteResp {
+				continue
+			}
+
+			buffer.Truncate(buffer.Len() - delimiterLen)
 			break
 		}
 	}
 
-	return &decoderBuffer, nil
+	return &buffer, nil
 }
@@ -0,0 +1,104 @@
+package universum
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newDelayedClient(t *testing.T, delay time.Duration) *Client {
+	return newFakeClient(t, func(cmd []interface{}) interface{} {
+		if cmd[0] == commandGet && cmd[1] == "slow" {
+			time.Sleep(delay)
+		}
+		return fakeReply(nil, RespRecordNotFound, "")
+	}, nil)
+}
+
+func TestSendCommand_ContextDeadline(t *testing.T) {
+	client := newDelayedClient(t, 500*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.Get(ctx, "slow")
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, ErrSocketReadFailed) {
+		t.Fatalf("Expected deadline exceeded read failure, got %v", err)
+	}
+	if elapsed > 250*time.Millisecond {
+		t.Fatalf("Expected command to give up at the context deadline, took %s", elapsed)
+	}
+	if client.pool.Len() != 0 {
+		t.Fatalf("Expected abandoned connection to be discarded, got %d live", client.pool.Len())
+	}
+
+	result, err := client.Get(context.Background(), "fast")
+	if err != nil || result.Code != RespRecordNotFound {
+		t.Fatalf("Expected next command to succeed on a fresh connection, got %v, %v", result, err)
+	}
+}
+
+func TestSendCommand_ContextCancel(t *testing.T) {
+	client := newDelayedClient(t, 500*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(30*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := client.Get(ctx, "slow")
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected cancelled error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Fatalf("Expected cancellation to interrupt the read, took %s", elapsed)
+	}
+	if client.pool.Len() != 0 {
+		t.Fatalf("Expected abandoned connection to be discarded, got %d live", client.pool.Len())
+	}
+}
+
+func TestSendCommand_ContextAlreadyDone(t *testing.T) {
+	client := newDelayedClient(t, 0)
+
+	if _, err := client.Get(context.Background(), "fast"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := client.Get(ctx, "fast"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected cancelled error, got %v", err)
+	}
+	if client.pool.IdleLen() != 1 {
+		t.Fatalf("Expected untouched connection to stay pooled, got %d idle", client.pool.IdleLen())
+	}
+}
+
+func TestCommands(t *testing.T) {
+	commands := Commands()
+	if len(commands) != 16 {
+		t.Fatalf("Expected 16 commands, got %d: %v", len(commands), commands)
+	}
+
+	for _, name := range commands {
+		if name == commandAuth || name == commandHello {
+			t.Fatalf("Expected connection setup commands to be left out, got %s", name)
+		}
+	}
+}
+
+func TestRespCodeName(t *testing.T) {
+	if name := RespCodeName(RespRecordFound); name != "RespRecordFound" {
+		t.Fatalf("Expected RespRecordFound, got %s", name)
+	}
+
+	if name := RespCodeName(4242); name != "4242" {
+		t.Fatalf("Expected an unknown code as its number, got %s", name)
+	}
+}
@@ -0,0 +1,309 @@
+package universum
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const DefaultEnvPrefix = "UNIVERSUM"
+
+// OptionsFromEnv builds validated Options from environment variables. Every key
+// of ParseURL plus host_addr, username and password is read from a variable named
+// after the prefix and the upper-cased key, e.g. UNIVERSUM_HOST_ADDR,
+// UNIVERSUM_POOL_SIZE or UNIVERSUM_CA_FILE. Other variables carrying the prefix
+// are ignored, since deployments may share it with other software; use
+// OptionsFromEnvStrict to report them.
+//
+// Parameters:
+// - prefix: The variable prefix, DefaultEnvPrefix if empty.
+//
+// Returns:
+// - *Options: The configured options, initialised with defaults.
+// - error: Returns an error wrapping ErrInvalidOption naming every offending variable.
+func OptionsFromEnv(prefix string) (*Options, error) {
+	return optionsFromEnv(prefix, false)
+}
+
+// OptionsFromEnvStrict works like OptionsFromEnv, but also reports variables
+// carrying the prefix which do not name an option, to catch typos.
+//
+// Parameters:
+// - prefix: The variable prefix, DefaultEnvPrefix if empty.
+//
+// Returns:
+// - *Options: The configured options, initialised with defaults.
+// - error: Returns an error wrapping ErrInvalidOption naming every offending or unknown variable.
+func OptionsFromEnvStrict(prefix string) (*Options, error) {
+	return optionsFromEnv(prefix, true)
+}
+
+// optionsFromEnv reads the options from the environment, reporting unknown
+// variables carrying the prefix when strict is set
+func optionsFromEnv(prefix string, strict bool) (*Options, error) {
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+	prefix = strings.TrimSuffix(prefix, "_") + "_"
+
+	envKey := func(name string) string {
+		return prefix + strings.ToUpper(name)
+	}
+
+	values := make(map[string]string)
+	for _, env := range os.Environ() {
+		key, value, _ := strings.Cut(env, "=")
+		if strings.HasPrefix(key, prefix) {
+			values[key] = value
+		}
+	}
+
+	opts := &Options{}
+	var errs []error
+
+	for _, param := range configParams {
+		key := envKey(param.name)
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+		delete(values, key)
+
+		if err := setOptionParam(opts, param, key, value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if strict {
+		unknown := make([]string, 0, len(values))
+		for key := range values {
+			unknown = append(unknown, key)
+		}
+		sort.Strings(unknown)
+
+		for _, key := range unknown {
+			errs = append(errs, fmt.Errorf("unknown environment variable %s: %w", key, ErrInvalidOption))
+		}
+	}
+
+	return finishConfig(opts, errs, envKey)
+}
+
+// OptionsFromFile builds validated Options from a JSON or YAML file, chosen by the
+// .json, .yaml or .yml extension. The file holds a single flat object whose keys are
+// those accepted by OptionsFromEnv in lower case, e.g.
+//
+//	host_addr: db.example.com:11191
+//	pool_size: 32
+//	read_timeout: 500ms
+//
+// Only a simple YAML subset is supported: one "key: value" pair per line, optionally
+// quoted values and # comments.
+//
+// Parameters:
+// - path: The path of the configuration file.
+//
+// Returns:
+// - *Options: The configured options, initialised with defaults.
+// - error: Returns an error wrapping ErrInvalidOption naming every offending key.
+func OptionsFromFile(path string) (*Options, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	var entries []configEntry
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		entries, err = parseJSONConfig(data)
+	case ".yaml", ".yml":
+		entries, err = parseYAMLConfig(data)
+	default:
+		return nil, fmt.Errorf("unsupported config file format %q, expected .json, .yaml or .yml: %w",
+			filepath.Ext(path), ErrInvalidOption)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("malformed config file %s: %w", path, err)
+	}
+
+	opts := &Options{}
+	var errs []error
+	seen := make(map[string]bool, len(entries))
+
+	for _, entry := range entries {
+		if seen[entry.key] {
+			errs = append(errs, fmt.Errorf("duplicate key %s: %w", entry.key, ErrInvalidOption))
+			continue
+		}
+		seen[entry.key] = true
+
+		param, ok := findOptionParam(configParams, entry.key)
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown key %s: %w", entry.key, ErrInvalidOption))
+			continue
+		}
+
+		if err := setOptionParam(opts, param, entry.key, entry.value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return finishConfig(opts, errs, func(name string) string { return name })
+}
+
+// configEntry is a single key and its textual value read from a config file
+type configEntry struct {
+	key   string
+	value string
+}
+
+// parseJSONConfig reads a flat JSON object, converting scalar values to text.
+// The object is read token by token so that duplicate keys are kept and
+// reported rather than silently overwritten.
+func parseJSONConfig(data []byte) ([]configEntry, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("expected a JSON object: %w", ErrInvalidOption)
+	}
+
+	var entries []configEntry
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", err, ErrInvalidOption)
+		}
+		key, _ := token.(string)
+
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return nil, fmt.Errorf("%v: %w", err, ErrInvalidOption)
+		}
+
+		switch value := value.(type) {
+		case string:
+			entries = append(entries, configEntry{key, value})
+		case json.Number:
+			entries = append(entries, configEntry{key, value.String()})
+		case bool:
+			entries = append(entries, configEntry{key, fmt.Sprint(value)})
+		default:
+			return nil, fmt.Errorf("key %s must hold a string, number or boolean: %w", key, ErrInvalidOption)
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrInvalidOption)
+	}
+
+	return entries, nil
+}
+
+// parseYAMLConfig reads "key: value" lines, skipping blank lines and # comments
+func parseYAMLConfig(data []byte) ([]configEntry, error) {
+	var entries []configEntry
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line == "---" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %d is not a \"key: value\" pair: %w", lineNo, ErrInvalidOption)
+		}
+
+		value, err := unquoteYAMLValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+
+		entries = append(entries, configEntry{key, value})
+	}
+
+	return entries, scanner.Err()
+}
+
+// unquoteYAMLValue strips quotes from a scalar, or a trailing comment from an unquoted one
+func unquoteYAMLValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	quote := value[0]
+	if quote != '"' && quote != '\'' {
+		if idx := strings.Index(value, " #"); idx >= 0 {
+			value = strings.TrimSpace(value[:idx])
+		}
+		return value, nil
+	}
+
+	end := strings.LastIndexByte(value, quote)
+	if end == 0 {
+		return "", fmt.Errorf("unterminated quoted value %s: %w", value, ErrInvalidOption)
+	}
+
+	if rest := strings.TrimSpace(value[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", fmt.Errorf("unexpected text after quoted value %s: %w", value, ErrInvalidOption)
+	}
+
+	return value[1:end], nil
+}
+
+// finishConfig validates options loaded from a configuration source and applies the
+// defaults, naming every invalid field by the key returned from keyOf.
+func finishConfig(opts *Options, errs []error, keyOf func(name string) string) (*Options, error) {
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, keyValidationErrors(err, keyOf)
+	}
+
+	opts.Init()
+	return opts, nil
+}
+
+// keyValidationErrors prefixes every OptionError reported by Options.Validate
+// with the configuration key of its field.
+func keyValidationErrors(err error, keyOf func(name string) string) error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return err
+	}
+
+	errs := joined.Unwrap()
+	keyed := make([]error, 0, len(errs))
+
+	for _, fieldErr := range errs {
+		var optErr *OptionError
+		if !errors.As(fieldErr, &optErr) {
+			keyed = append(keyed, fieldErr)
+			continue
+		}
+
+		for _, param := range configParams {
+			if param.field == optErr.Field {
+				fieldErr = fmt.Errorf("%s: %w", keyOf(param.name), fieldErr)
+				break
+			}
+		}
+
+		keyed = append(keyed, fieldErr)
+	}
+
+	return errors.Join(keyed...)
+}
@@ -0,0 +1,157 @@
+package universum
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOptionsFromEnv(t *testing.T) {
+	t.Setenv("UNIVERSUM_HOST_ADDR", "db.example.com:11192")
+	t.Setenv("UNIVERSUM_POOL_SIZE", "32")
+	t.Setenv("UNIVERSUM_READ_TIMEOUT", "500ms")
+	t.Setenv("UNIVERSUM_TLS", "true")
+	t.Setenv("UNIVERSUM_CA_FILE", "/etc/universum/ca.pem")
+	t.Setenv("UNIVERSUM_PASSWORD", "secret")
+
+	opts, err := OptionsFromEnv("")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if opts.HostAddr != "db.example.com:11192" || opts.ConnPoolsize != 32 {
+		t.Fatalf("Expected host and pool size from env, got %s %d", opts.HostAddr, opts.ConnPoolsize)
+	}
+	if opts.ReadTimeout != 500*time.Millisecond {
+		t.Fatalf("Expected read timeout 500ms, got %v", opts.ReadTimeout)
+	}
+	if !opts.EnableTLS || opts.CAFile != "/etc/universum/ca.pem" || opts.Password != "secret" {
+		t.Fatalf("Expected TLS settings and password from env, got %+v", opts)
+	}
+	if opts.WriteTimeout != DefaultWriteTimeout {
+		t.Fatalf("Expected default write timeout, got %v", opts.WriteTimeout)
+	}
+}
+
+func TestOptionsFromEnv_Errors(t *testing.T) {
+	t.Setenv("CACHE_POOL_SIZE", "many")
+	t.Setenv("CACHE_READ_TIMEOUT", "10s")
+	t.Setenv("CACHE_POOL_SIZ", "4")
+
+	_, err := OptionsFromEnv("CACHE")
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("Expected ErrInvalidOption, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "CACHE_POOL_SIZE") || strings.Contains(err.Error(), "unknown environment variable") {
+		t.Fatalf("Expected error to name CACHE_POOL_SIZE and ignore the unknown variable, got %v", err)
+	}
+
+	t.Setenv("CACHE_POOL_SZ", "4")
+	_, err = OptionsFromEnvStrict("CACHE")
+	for _, key := range []string{"CACHE_POOL_SIZE", "unknown environment variable CACHE_POOL_SIZ"} {
+		if err == nil || !strings.Contains(err.Error(), key) {
+			t.Fatalf("Expected strict error to name %s, got %v", key, err)
+		}
+	}
+	if first := strings.Index(err.Error(), "CACHE_POOL_SIZ:"); first < 0 || first > strings.Index(err.Error(), "CACHE_POOL_SZ:") {
+		t.Fatalf("Expected unknown variables to be reported in sorted order, got %v", err)
+	}
+
+	t.Setenv("CACHE_POOL_SIZE", "8")
+	os.Unsetenv("CACHE_POOL_SIZ")
+
+	_, err = OptionsFromEnv("CACHE_")
+	if err == nil || !strings.HasPrefix(err.Error(), "CACHE_READ_TIMEOUT: ") {
+		t.Fatalf("Expected validation error naming CACHE_READ_TIMEOUT, got %v", err)
+	}
+}
+
+func writeConfigFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestOptionsFromFile_JSON(t *testing.T) {
+	path := writeConfigFile(t, "universum.json", `{
+		"host_addr": "db.example.com:11191",
+		"pool_size": 24,
+		"read_timeout": "750ms",
+		"readonly": true,
+		"breaker_failure_rate": 0.3
+	}`)
+
+	opts, err := OptionsFromFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if opts.HostAddr != "db.example.com:11191" || opts.ConnPoolsize != 24 || !opts.IsReadonly {
+		t.Fatalf("Expected options from JSON, got %+v", opts)
+	}
+	if opts.ReadTimeout != 750*time.Millisecond || opts.BreakerFailureRate != 0.3 {
+		t.Fatalf("Expected read timeout and failure rate from JSON, got %v %v", opts.ReadTimeout, opts.BreakerFailureRate)
+	}
+}
+
+func TestOptionsFromFile_YAML(t *testing.T) {
+	path := writeConfigFile(t, "universum.yaml", `
+# universum client
+---
+host_addr: "unix:///var/run/universum.sock"
+pool_size: 12   # per instance
+write_timeout: 2s
+client_name: 'billing # worker'
+`)
+
+	opts, err := OptionsFromFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if opts.HostAddr != "unix:///var/run/universum.sock" || opts.ConnPoolsize != 12 {
+		t.Fatalf("Expected options from YAML, got %+v", opts)
+	}
+	if opts.WriteTimeout != 2*time.Second || opts.ClientName != "billing # worker" {
+		t.Fatalf("Expected write timeout and client name from YAML, got %v %q", opts.WriteTimeout, opts.ClientName)
+	}
+}
+
+func TestOptionsFromFile_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		message string
+	}{
+		{"bad.json", `{"read_timeout": 5}`, "read_timeout"},
+		{"nested.json", `{"tls": {"enabled": true}}`, "key tls"},
+		{"unknown.yml", "pool_sise: 4\n", "unknown key pool_sise"},
+		{"dup.yaml", "pool_size: 4\npool_size: 8\n", "duplicate key pool_size"},
+		{"dup.json", `{"pool_size": 4, "pool_size": 8}`, "duplicate key pool_size"},
+		{"array.json", `[{"pool_size": 4}]`, "expected a JSON object"},
+		{"ciphers.yaml", "tls_cipher_suites: TLS_NOT_A_SUITE\n", "tls_cipher_suites"},
+		{"syntax.yaml", "pool_size 4\n", "line 1"},
+		{"clamped.yaml", "pool_size: 70000\n", "pool_size: ConnPoolsize"},
+		{"config.toml", "pool_size = 4\n", "unsupported config file format"},
+	}
+
+	for _, test := range tests {
+		_, err := OptionsFromFile(writeConfigFile(t, test.name, test.content))
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Fatalf("Expected ErrInvalidOption for %s, got %v", test.name, err)
+		}
+		if !strings.Contains(err.Error(), test.message) {
+			t.Fatalf("Expected error for %s to mention %q, got %v", test.name, test.message, err)
+		}
+	}
+
+	if _, err := OptionsFromFile(filepath.Join(t.TempDir(), "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Expected missing file error, got %v", err)
+	}
+}
@@ -4,18 +4,24 @@ import (
 	"bufio"
 	"context"
 	"crypto/tls"
-	"crypto/x509"
 	"fmt"
 	"net"
-	"os"
+	"strings"
 	"sync/atomic"
 	"time"
 )
 
 const tcpDialer string = "tcp"
+const unixDialer string = "unix"
+
+// unixAddrPrefix marks a HostAddr as the path of a Unix domain socket
+const unixAddrPrefix string = "unix://"
 
 var noDeadline time.Time = time.Time{}
 
+// aLongTimeAgo is a deadline in the past, used to interrupt blocked socket I/O
+var aLongTimeAgo time.Time = time.Unix(1, 0)
+
 type connInterface interface {
 	write(content []byte) (int, error)
 	close() error
@@ -28,11 +34,14 @@ type connInterface interface {
 	getPooled() bool
 	getReader() *bufio.Reader
 	getWriter() *bufio.Writer
+	getAuthGeneration() int64
+	deadline(ctx context.Context, timeout time.Duration) time.Time
 
 	setUsedAt(t time.Time)
 	setInUse(state bool)
 	setPooled(pooled bool)
 	setCreatedAt(time.Time)
+	setAuthGeneration(generation int64)
 }
 
 // Conn represents a connection structure
@@ -44,6 +53,7 @@ type Conn struct {
 	createdAt time.Time
 	usedAt    int64
 	inUse     int32
+	authGen   int64
 }
 
 // Write writes content to the connection's buffer
@@ -63,13 +73,13 @@ func (c *Conn) setCreatedAt(t time.Time) {
 
 // GetUsedAt returns the last time the connection was used
 func (c *Conn) getUsedAt() time.Time {
-	unix := atomic.LoadInt64(&c.usedAt)
-	return time.Unix(unix, 0)
+	unixNano := atomic.LoadInt64(&c.usedAt)
+	return time.Unix(0, unixNano)
 }
 
 // SetUsedAt sets the time when the connection was last used
 func (c *Conn) setUsedAt(t time.Time) {
-	atomic.StoreInt64(&c.usedAt, t.Unix())
+	atomic.StoreInt64(&c.usedAt, t.UnixNano())
 }
 
 // InUse returns whether the connection is currently in use
@@ -116,6 +126,16 @@ func (c *Conn) getWriter() *bufio.Writer {
 	return c.writer
 }
 
+// GetAuthGeneration returns the generation of the credentials the connection was authenticated with
+func (c *Conn) getAuthGeneration() int64 {
+	return atomic.LoadInt64(&c.authGen)
+}
+
+// SetAuthGeneration records the generation of the credentials the connection was authenticated with
+func (c *Conn) setAuthGeneration(generation int64) {
+	atomic.StoreInt64(&c.authGen, generation)
+}
+
 // Close closes the connection and marks it as no longer in use
 func (c *Conn) close() error {
 	c.setInUse(false)
@@ -151,47 +171,35 @@ func (c *Conn) deadline(ctx context.Context, timeout time.Duration) time.Time {
 	return noDeadline
 }
 
-// newConnection creates a new connection to the specified address
-func newConnection(opts *Options) (connInterface, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), opts.DialTimeout*time.Second)
+// newConnection creates a new connection to the specified address, giving up
+// when either the dial timeout elapses or the context is done. The connection
+// is secured with tlsConfig unless it is nil.
+func newConnection(ctx context.Context, opts *Options, tlsConfig *tls.Config) (connInterface, error) {
+	ctx, cancel := context.WithTimeout(ctx, opts.DialTimeout)
 	defer cancel()
 
-	var dialer net.Dialer = net.Dialer{}
-	var tlsConfig *tls.Config
 	var retryCount int64 = 0
 	var dialedConn net.Conn
 	var connErr error
 
-	if opts.EnableTLS {
-		cert, err := tls.LoadX509KeyPair(opts.TLSCertFile, opts.TLSKeyFile)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load TLS certificate and key: %w", err)
-		}
-
-		caCertPool := x509.NewCertPool()
-		if opts.CAFile != "" {
-			caCert, err := os.ReadFile(opts.CAFile)
-			if err != nil {
-				return nil, fmt.Errorf("failed to load CA certificate: %w", err)
-			}
-			caCertPool.AppendCertsFromPEM(caCert)
-		}
+	network, address := dialTarget(opts.HostAddr)
 
-		tlsConfig = &tls.Config{
-			Certificates:       []tls.Certificate{cert},
-			RootCAs:            caCertPool,
-			InsecureSkipVerify: opts.InsecureSkipVerify, // Option to skip server cert verification (not recommended)
-		}
+	dial := opts.Dialer
+	if dial == nil {
+		dialer := &net.Dialer{KeepAlive: opts.KeepAlive}
+		dial = dialer.DialContext
 	}
 
 	for retryCount < opts.MaxRetries {
+		if retryCount > 0 && opts.RetryBackoff > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(opts.RetryBackoff):
+			}
+		}
 		retryCount++
 
-		if opts.EnableTLS {
-			dialedConn, connErr = tls.DialWithDialer(&dialer, tcpDialer, opts.HostAddr, tlsConfig)
-		} else {
-			dialedConn, connErr = dialer.DialContext(ctx, tcpDialer, opts.HostAddr)
-		}
+		dialedConn, connErr = dialConn(ctx, dial, network, address, tlsConfig, opts)
 
 		if connErr != nil {
 			if ctx.Err() == context.DeadlineExceeded {
@@ -204,16 +212,22 @@ func newConnection(opts *Options) (connInterface, error) {
 
 			continue
 		}
+
+		break
 	}
 
 	if connErr != nil {
 		return nil, connErr
 	}
 
+	if opts.Recorder != nil {
+		dialedConn = opts.Recorder.wrap(dialedConn)
+	}
+
 	conn := &Conn{
 		netconn:   dialedConn,
-		reader:    bufio.NewReader(dialedConn),
-		writer:    bufio.NewWriter(dialedConn),
+		reader:    bufio.NewReaderSize(dialedConn, opts.ReadBufferSize),
+		writer:    bufio.NewWriterSize(dialedConn, opts.WriteBufferSize),
 		createdAt: time.Now(),
 		pooled:    true,
 	}
@@ -240,3 +254,75 @@ func newConnection(opts *Options) (connInterface, error) {
 
 	return conn, nil
 }
+
+// dialTarget splits HostAddr into the network and address to dial, treating
+// addresses prefixed with unix:// as Unix domain socket paths
+func dialTarget(hostAddr string) (string, string) {
+	if strings.HasPrefix(hostAddr, unixAddrPrefix) {
+		return unixDialer, strings.TrimPrefix(hostAddr, unixAddrPrefix)
+	}
+
+	return tcpDialer, hostAddr
+}
+
+// dialConn dials the address with the given dialer, applies the TCP tuning
+// options and, if tlsConfig is set, performs the TLS handshake over the
+// dialed connection within the context deadline
+func dialConn(ctx context.Context, dial func(ctx context.Context, network, addr string) (net.Conn, error),
+	network, address string, tlsConfig *tls.Config, opts *Options) (net.Conn, error) {
+
+	rawConn, err := dial(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tuneConn(rawConn, opts); err != nil {
+		rawConn.Close()
+		return nil, err
+	}
+
+	if tlsConfig == nil {
+		return rawConn, nil
+	}
+
+	if tlsConfig.ServerName == "" && network == tcpDialer {
+		if host, _, err := net.SplitHostPort(address); err == nil {
+			tlsConfig = tlsConfig.Clone()
+			tlsConfig.ServerName = host
+		}
+	}
+
+	tlsConn := tls.Client(rawConn, tlsConfig)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		rawConn.Close()
+		return nil, err
+	}
+
+	return tlsConn, nil
+}
+
+// tuneConn applies the TCP options to TCP connections, other connections
+// such as Unix domain sockets or in-memory pipes are left untouched
+func tuneConn(netConn net.Conn, opts *Options) error {
+	tcpConn, ok := netConn.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+
+	if err := tcpConn.SetNoDelay(!opts.DisableTCPNoDelay); err != nil {
+		return err
+	}
+
+	if opts.KeepAlive < 0 {
+		return tcpConn.SetKeepAlive(false)
+	}
+
+	if opts.KeepAlive > 0 {
+		if err := tcpConn.SetKeepAlive(true); err != nil {
+			return err
+		}
+		return tcpConn.SetKeepAlivePeriod(opts.KeepAlive)
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"net"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -22,17 +23,22 @@ func (m *MockNetConn) SetWriteDeadline(t time.Time) error { return nil }
 
 // TestNewConnection tests the creation of a new connection
 func TestNewConnection(t *testing.T) {
+	server := newFakeServer(t, func(cmd []interface{}) interface{} {
+		return fakeReply("OK", RespPingSuccess, "")
+	})
+
 	opts := &Options{
-		HostAddr:    "localhost:11191",
+		HostAddr:    server.addr(),
 		DialTimeout: 1 * time.Second,
 		MaxRetries:  1,
 	}
 
 	opts.Init()
-	_, err := newConnection(opts)
+	conn, err := newConnection(context.Background(), opts, nil)
 	if err != nil {
-		t.Fatalf("Expected connection dial failed error, got %v", err)
+		t.Fatalf("Expected no error, got %v", err)
 	}
+	defer conn.close()
 }
 
 // TestWrite tests the write functionality
@@ -120,3 +126,81 @@ func TestDeadline(t *testing.T) {
 		t.Errorf("Expected deadline to be %v, got %v", expectedDeadline, deadline)
 	}
 }
+
+// TestNewConnectionUnixSocket tests dialing a server over a Unix domain socket
+func TestNewConnectionUnixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "universum.sock")
+	newFakeServerOn(t, "unix", socketPath, func(cmd []interface{}) interface{} {
+		return fakeReply("PONG", RespPingSuccess, "OK")
+	})
+
+	opts := &Options{HostAddr: "unix://" + socketPath}
+	opts.Init()
+
+	conn, err := newConnection(context.Background(), opts, nil)
+	if err != nil {
+		t.Fatalf("Expected to dial the unix socket, got %v", err)
+	}
+	defer conn.close()
+
+	if network := conn.getRemoteAddr().Network(); network != "unix" {
+		t.Fatalf("Expected a unix connection, got %s", network)
+	}
+
+	result, err := execOnConn(context.Background(), conn, opts, newCommandTrace(), commandPing)
+	if err != nil || result.code != RespPingSuccess {
+		t.Fatalf("Expected PING over the unix socket to succeed, got %v, %v", result, err)
+	}
+}
+
+// TestNewConnectionCustomDialer tests that a custom dialer is used with the parsed target
+func TestNewConnectionCustomDialer(t *testing.T) {
+	server := newFakeServer(t, func(cmd []interface{}) interface{} {
+		return fakeReply("PONG", RespPingSuccess, "OK")
+	})
+
+	var dialedNetwork, dialedAddr string
+	pipeDialer := server.pipeDialer()
+
+	opts := &Options{
+		HostAddr:        "universum.internal:11191",
+		ReadBufferSize:  64 * 1024,
+		WriteBufferSize: 32 * 1024,
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialedNetwork, dialedAddr = network, addr
+			return pipeDialer(ctx, network, addr)
+		},
+	}
+	opts.Init()
+
+	conn, err := newConnection(context.Background(), opts, nil)
+	if err != nil {
+		t.Fatalf("Expected to dial with the custom dialer, got %v", err)
+	}
+	defer conn.close()
+
+	if dialedNetwork != "tcp" || dialedAddr != "universum.internal:11191" {
+		t.Fatalf("Expected dialer to receive tcp universum.internal:11191, got %s %s", dialedNetwork, dialedAddr)
+	}
+	if size := conn.getReader().Size(); size != 64*1024 {
+		t.Errorf("Expected read buffer of 64KiB, got %d", size)
+	}
+	if size := conn.getWriter().Size(); size != 32*1024 {
+		t.Errorf("Expected write buffer of 32KiB, got %d", size)
+	}
+
+	result, err := execOnConn(context.Background(), conn, opts, newCommandTrace(), commandPing)
+	if err != nil || result.code != RespPingSuccess {
+		t.Fatalf("Expected PING over the pipe to succeed, got %v, %v", result, err)
+	}
+}
+
+// TestDialTarget tests splitting HostAddr into network and address
+func TestDialTarget(t *testing.T) {
+	if network, addr := dialTarget("localhost:11191"); network != "tcp" || addr != "localhost:11191" {
+		t.Errorf("Expected tcp localhost:11191, got %s %s", network, addr)
+	}
+	if network, addr := dialTarget("unix:///run/universum.sock"); network != "unix" || addr != "/run/universum.sock" {
+		t.Errorf("Expected unix /run/universum.sock, got %s %s", network, addr)
+	}
+}
@@ -10,6 +10,10 @@ var (
 	ErrConnectionConfigFailed = errors.New("CONN_CONFIG_FAILED")
 
 	ErrConnectionPoolClosed = errors.New("CONN_POOL_CLOSED")
+	ErrPoolWarmupFailed     = errors.New("POOL_WARMUP_FAILED")
+	ErrCircuitOpen          = errors.New("CIRCUIT_OPEN")
+	ErrRateLimited          = errors.New("RATE_LIMITED")
+	ErrTooManyInFlight      = errors.New("TOO_MANY_IN_FLIGHT")
 
 	ErrCommandEncodingFailed = errors.New("CMD_ENCODING_FAILED")
 	ErrSocketWriteFailed     = errors.New("SOCKET_WRITE_FAILED")
@@ -18,13 +22,26 @@ var (
 	ErrSocketReadFailed      = errors.New("SOCKET_READ_FAILED")
 
 	ErrMalformedResponseReceived = errors.New("MALFORMED_RESPONSE_RECEIVED")
+	ErrReplyTooLarge             = errors.New("REPLY_TOO_LARGE")
+	ErrReplyTooDeep              = errors.New("REPLY_TOO_DEEP")
 	ErrServerRejectedRequest     = errors.New("SERVER_REJECTED_REQUEST")
+	ErrServerBusy                = errors.New("SERVER_BUSY")
+	ErrServerShuttingDown        = errors.New("SERVER_SHUTTING_DOWN")
 
 	ErrInvalidRequest  = errors.New("INVALID_REQUEST")
+	ErrInvalidOption   = errors.New("INVALID_OPTION")
 	ErrClientReadonly  = errors.New("CLIENT_READONLY")
 	ErrInvalidDatatype = errors.New("INVALID_DATATYPE")
+
+	ErrAuthFailed             = errors.New("AUTH_FAILED")
+	ErrCredentialsUnavailable = errors.New("CREDENTIALS_UNAVAILABLE")
+	ErrUnsupportedByServer    = errors.New("UNSUPPORTED_BY_SERVER")
+
+	ErrInvalidRecording = errors.New("INVALID_RECORDING")
+	ErrReplayMismatch   = errors.New("REPLAY_MISMATCH")
 )
 
 var (
 	errUnexpectedRead = errors.New("unexpected read from socket")
+	errPoolExhausted  = errors.New("no free slot left in connection pool")
 )
@@ -0,0 +1,227 @@
+package universum
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"net"
+	"sync"
+	"testing"
+)
+
+// fakeHandler receives a decoded command (name followed by its arguments)
+// and returns the value to be encoded back as the reply.
+type fakeHandler func(cmd []interface{}) interface{}
+
+// fakeServer is a minimal RESP3 server used by the unit tests which need a
+// remote peer, replying to every command via the configured handler.
+type fakeServer struct {
+	listener   net.Listener
+	handler    fakeHandler
+	perConnHdl func() fakeHandler
+
+	mu    sync.Mutex
+	conns []net.Conn
+	wg    sync.WaitGroup
+}
+
+func newFakeServer(t testing.TB, handler fakeHandler) *fakeServer {
+	t.Helper()
+	return newFakeServerOn(t, "tcp", "127.0.0.1:0", handler)
+}
+
+// newFakeServerOn starts a fake server listening on the given network and address
+func newFakeServerOn(t testing.TB, network, address string, handler fakeHandler) *fakeServer {
+	t.Helper()
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		t.Fatalf("failed to start fake server: %v", err)
+	}
+
+	server := &fakeServer{listener: listener, handler: rejectHello(handler)}
+	server.wg.Add(1)
+	go server.serve()
+
+	t.Cleanup(server.close)
+	return server
+}
+
+// newFakeTLSServer starts a fake server on a local TCP port which requires a
+// TLS handshake with the given server configuration
+func newFakeTLSServer(t *testing.T, config *tls.Config, handler fakeHandler) *fakeServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start fake TLS server: %v", err)
+	}
+
+	server := &fakeServer{listener: tls.NewListener(listener, config), handler: rejectHello(handler)}
+	server.wg.Add(1)
+	go server.serve()
+
+	t.Cleanup(server.close)
+	return server
+}
+
+// rejectHello answers HELLO like a server which predates the handshake, so that
+// the handler only sees the commands under test
+func rejectHello(handler fakeHandler) fakeHandler {
+	return func(cmd []interface{}) interface{} {
+		if cmd[0] == commandHello {
+			return fakeReply(nil, RespInvalidCmdInput, "unknown command")
+		}
+		return handler(cmd)
+	}
+}
+
+// newFakeServerPerConn starts a fake server which creates a separate handler
+// for every connection, so that handlers can keep per-connection state. The
+// handlers receive every command including HELLO.
+func newFakeServerPerConn(t *testing.T, newHandler func() fakeHandler) *fakeServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start fake server: %v", err)
+	}
+
+	server := &fakeServer{listener: listener, perConnHdl: newHandler}
+	server.wg.Add(1)
+	go server.serve()
+
+	t.Cleanup(server.close)
+	return server
+}
+
+// newFakeClient starts a fake server replying via the handler and returns a
+// client connected to it. configure, if not nil, adjusts the options before
+// the client is created.
+func newFakeClient(t testing.TB, handler fakeHandler, configure func(opts *Options)) *Client {
+	t.Helper()
+	return newClientFor(t, newFakeServer(t, handler), configure)
+}
+
+// newClientFor returns a client connected to the fake server, which is closed
+// when the test ends. configure, if not nil, adjusts the options before the
+// client is created.
+func newClientFor(t testing.TB, server *fakeServer, configure func(opts *Options)) *Client {
+	t.Helper()
+
+	opts := mockOptions()
+	opts.HostAddr = server.addr()
+	if configure != nil {
+		configure(opts)
+	}
+
+	client, err := NewClient(opts)
+	if err != nil {
+		t.Fatalf("Expected no error while creating client, got %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+// fakeReply builds the standard [value, code, message] reply triplet.
+func fakeReply(value interface{}, code int64, message string) []interface{} {
+	return []interface{}{value, code, message}
+}
+
+func (s *fakeServer) addr() string {
+	return s.listener.Addr().String()
+}
+
+// pipeDialer returns a dialer which connects to the server over an in-memory net.Pipe
+func (s *fakeServer) pipeDialer() func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		clientConn, serverConn := net.Pipe()
+
+		s.mu.Lock()
+		s.conns = append(s.conns, serverConn)
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.handle(serverConn)
+
+		return clientConn, nil
+	}
+}
+
+func (s *fakeServer) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.conns = append(s.conns, conn)
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeServer) handle(conn net.Conn) {
+	defer s.wg.Done()
+	defer conn.Close()
+
+	handler := s.handler
+	if s.perConnHdl != nil {
+		handler = s.perConnHdl()
+	}
+
+	var request []byte
+	chunk := make([]byte, 32*1024)
+
+	for {
+		n, err := conn.Read(chunk)
+		if err != nil {
+			return
+		}
+		request = append(request, chunk[:n]...)
+
+		// the decoder reads bulk strings with a single Read, so only decode complete requests
+		if validateResp(request, 0) == errIncompleteResp {
+			continue
+		}
+
+		decoded, err := decodeResp(bufio.NewReaderSize(bytes.NewReader(request), len(request)))
+		request = request[:0]
+		if err != nil {
+			return
+		}
+
+		cmd, ok := decoded.([]interface{})
+		if !ok || len(cmd) == 0 {
+			return
+		}
+
+		encoded, err := encodeResp(handler(cmd))
+		if err != nil {
+			return
+		}
+
+		if _, err := conn.Write([]byte(encoded + remoteByteDelimiter)); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeServer) close() {
+	s.listener.Close()
+
+	s.mu.Lock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
@@ -0,0 +1,300 @@
+package universum
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fuzzReplies are valid replies of the server, seeding the fuzz targets
+var fuzzReplies = []interface{}{
+	fakeReply(nil, RespRecordNotFound, ""),
+	fakeReply(map[string]interface{}{"Value": "hello"}, RespRecordFound, ""),
+	fakeReply(true, RespRecordUpdated, ""),
+	fakeReply(int64(42), RespRecordUpdated, ""),
+	fakeReply(3.5, RespRecordFound, ""),
+	fakeReply([]interface{}{int64(1), "two", false}, RespRecordFound, ""),
+	fakeReply(map[string]interface{}{"a": true, "b": false}, RespMsetCompleted, ""),
+	fakeReply(map[string]interface{}{"a": map[string]interface{}{"Code": int64(RespRecordFound), "Value": int64(1)}}, RespMgetCompleted, ""),
+	fakeReply("OK", RespPingSuccess, ""),
+	fakeReply(remoteByteDelimiter, RespRecordFound, ""),
+	fakeReply(nil, RespInvalidCmdInput, "unknown command"),
+}
+
+// fuzzHostileReplies are malformed replies which used to panic or hang the decoder
+var fuzzHostileReplies = []string{
+	"",
+	"*-5\r\n",
+	"$-3\r\n",
+	"%-2\r\n",
+	"%2\r\n*1\r\n:1\r\n:2\r\n",
+	"%2\r\n%2\r\n:1\r\n:2\r\n:3\r\n",
+	"%3\r\n+a\r\n",
+	"*999999999\r\n",
+	"%999999999\r\n",
+	"$999999999\r\nabc\r\n",
+	"$5\r\nab\r\n",
+	"=0\r\n\r\n",
+	"*3\r\n_\r\n:abc\r\n+\r\n",
+	"*3\r\n_\r\n:5001\r\n",
+	"*3\r\n_\r\n:5001\r\n+\r\n+trailing\r\n",
+	"?\r\n",
+	strings.Repeat("*1\r\n", 100) + ":1\r\n",
+}
+
+// scriptedConn is a connection which answers every request with its reply,
+// and then reports the end of the stream
+type scriptedConn struct {
+	reply func(request []byte) []byte
+	chunk int
+
+	mu      sync.Mutex
+	request bytes.Buffer
+	pending bytes.Buffer
+}
+
+func (c *scriptedConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.request.Write(p)
+}
+
+func (c *scriptedConn) Read(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.pending.Len() == 0 && c.request.Len() > 0 {
+		c.pending.Reset()
+		c.pending.Write(c.reply(c.request.Bytes()))
+		c.request.Reset()
+	}
+
+	if c.pending.Len() == 0 {
+		return 0, io.EOF
+	}
+
+	if c.chunk > 0 && len(p) > c.chunk {
+		p = p[:c.chunk]
+	}
+
+	return c.pending.Read(p)
+}
+
+func (c *scriptedConn) Close() error                       { return nil }
+func (c *scriptedConn) LocalAddr() net.Addr                { return replayAddr{} }
+func (c *scriptedConn) RemoteAddr() net.Addr               { return replayAddr{} }
+func (c *scriptedConn) SetDeadline(t time.Time) error      { return nil }
+func (c *scriptedConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *scriptedConn) SetWriteDeadline(t time.Time) error { return nil }
+
+func FuzzParseReply(f *testing.F) {
+	for _, reply := range fuzzReplies {
+		encoded, err := encodeResp(reply)
+		if err != nil {
+			f.Fatalf("Expected no error while encoding %v, got %v", reply, err)
+		}
+		f.Add([]byte(encoded + remoteByteDelimiter))
+	}
+
+	for _, reply := range fuzzHostileReplies {
+		f.Add([]byte(reply + remoteByteDelimiter))
+	}
+
+	opts := &Options{ReadTimeout: time.Second, MaxReplySize: 1 << 16, MaxReplyDepth: DefaultMaxReplyDepth}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		netconn := &scriptedConn{}
+		netconn.pending.Write(data)
+		conn := &Conn{netconn: netconn, reader: bufio.NewReader(netconn), writer: bufio.NewWriter(netconn)}
+
+		buffer, err := readUntilDelimiter(context.Background(), conn, opts, remoteByteDelimiter)
+		if err != nil {
+			return
+		}
+
+		if int64(buffer.Len()) > opts.MaxReplySize {
+			t.Fatalf("Expected a reply of at most %d bytes, got %d", opts.MaxReplySize, buffer.Len())
+		}
+
+		result, err := parseReply(buffer.Bytes(), opts)
+		if (result == nil) == (err == nil) {
+			t.Fatalf("Expected either a result or an error, got %v and %v", result, err)
+		}
+	})
+}
+
+// checkFuzzResult fails the fuzz test unless exactly one of result and err is set
+func checkFuzzResult[R any](t *testing.T, command string, result *R, err error) {
+	t.Helper()
+
+	if (result == nil) == (err == nil) {
+		t.Fatalf("Expected either a result or an error for %s, got %v and %v", command, result, err)
+	}
+}
+
+func FuzzCommandResults(f *testing.F) {
+	for _, reply := range fuzzReplies {
+		encoded, err := encodeResp(reply)
+		if err != nil {
+			f.Fatalf("Expected no error while encoding %v, got %v", reply, err)
+		}
+		f.Add([]byte(encoded))
+	}
+
+	for _, reply := range fuzzHostileReplies {
+		f.Add([]byte(reply))
+	}
+
+	var payload atomic.Pointer[[]byte]
+	helloRejection, _ := encodeResp(fakeReply(nil, RespInvalidCmdInput, "unknown command"))
+
+	reply := func(request []byte) []byte {
+		decoded, err := decodeResp(bufio.NewReader(bytes.NewReader(request)))
+		if err == nil && commandName(decoded) == commandHello {
+			return []byte(helloRejection + remoteByteDelimiter)
+		}
+		return append(append([]byte{}, *payload.Load()...), remoteByteDelimiter...)
+	}
+
+	client, err := NewClient(&Options{
+		HostAddr:      "fuzz:11191",
+		ConnPoolsize:  1,
+		MaxRetries:    1,
+		ReadTimeout:   time.Second,
+		MaxReplySize:  1 << 16,
+		MaxReplyDepth: DefaultMaxReplyDepth,
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return &scriptedConn{reply: reply}, nil
+		},
+	})
+	if err != nil {
+		f.Fatalf("Expected no error while creating client, got %v", err)
+	}
+	defer client.Close()
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		payload.Store(&data)
+		ctx := context.Background()
+
+		getResult, err := client.Get(ctx, "key")
+		checkFuzzResult(t, commandGet, getResult, err)
+
+		setResult, err := client.Set(ctx, "key", "value", 0)
+		checkFuzzResult(t, commandSet, setResult, err)
+
+		existsResult, err := client.Exists(ctx, "key")
+		checkFuzzResult(t, commandExists, existsResult, err)
+
+		deleteResult, err := client.Delete(ctx, "key")
+		checkFuzzResult(t, commandDelete, deleteResult, err)
+
+		incrResult, err := client.Increment(ctx, "key", 1)
+		checkFuzzResult(t, commandIncr, incrResult, err)
+
+		decrResult, err := client.Decrement(ctx, "key", 1)
+		checkFuzzResult(t, commandDecr, decrResult, err)
+
+		appendResult, err := client.Append(ctx, "key", "value")
+		checkFuzzResult(t, commandAppend, appendResult, err)
+
+		mgetResult, err := client.MGet(ctx, []string{"a", "b"})
+		checkFuzzResult(t, commandMget, mgetResult, err)
+
+		msetResult, err := client.MSet(ctx, map[string]interface{}{"a": 1, "b": 2})
+		checkFuzzResult(t, commandMset, msetResult, err)
+
+		mdeleteResult, err := client.MDelete(ctx, []string{"a", "b"})
+		checkFuzzResult(t, commandMdelete, mdeleteResult, err)
+
+		ttlResult, err := client.TTL(ctx, "key")
+		checkFuzzResult(t, commandTtl, ttlResult, err)
+
+		expireResult, err := client.Expire(ctx, "key", 10)
+		checkFuzzResult(t, commandExpire, expireResult, err)
+
+		pingResult, err := client.Ping(ctx)
+		checkFuzzResult(t, commandPing, pingResult, err)
+
+		infoResult, err := client.Info(ctx)
+		checkFuzzResult(t, commandInfo, infoResult, err)
+
+		helpResult, err := client.Help(ctx)
+		checkFuzzResult(t, commandHelp, helpResult, err)
+
+		snapshotResult, err := client.Snapshot(ctx)
+		checkFuzzResult(t, commandSnapshot, snapshotResult, err)
+	})
+}
+
+func TestParseReply_Limits(t *testing.T) {
+	opts := &Options{MaxReplySize: DefaultMaxReplySize, MaxReplyDepth: 4}
+
+	nested := strings.Repeat("*1\r\n", 5) + ":1\r\n"
+	if _, err := parseReply([]byte("*3\r\n"+nested+":5001\r\n+\r\n"), opts); !errors.Is(err, ErrReplyTooDeep) {
+		t.Fatalf("Expected ErrReplyTooDeep, got %v", err)
+	}
+
+	for _, reply := range fuzzHostileReplies {
+		if _, err := parseReply([]byte(reply), opts); err == nil {
+			t.Fatalf("Expected an error for the hostile reply %q", reply)
+		}
+	}
+
+	long := strings.Repeat("x", 3*4096)
+	for _, encoded := range []string{"+" + long, "$" + strconv.Itoa(len(long)) + "\r\n" + long} {
+		result, err := parseReply([]byte("*3\r\n"+encoded+"\r\n:1000\r\n+\r\n"), opts)
+		if err != nil {
+			t.Fatalf("Expected no error for a value longer than the reader buffer, got %v", err)
+		}
+
+		if result.value != long {
+			t.Fatalf("Expected a value of %d bytes, got %v", len(long), result.value)
+		}
+	}
+
+	result, err := parseReply([]byte("*3\r\n$4\r\n\x04\x04\x04\x04\r\n:5001\r\n+\r\n"), opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.value != remoteByteDelimiter || result.code != RespRecordNotFound {
+		t.Fatalf("Expected the delimiter as value with code %d, got %v", RespRecordNotFound, result)
+	}
+}
+
+func TestReadUntilDelimiter_Limits(t *testing.T) {
+	read := func(data string, opts *Options) (*bytes.Buffer, error) {
+		netconn := &scriptedConn{chunk: 4}
+		netconn.pending.WriteString(data)
+		conn := &Conn{netconn: netconn, reader: bufio.NewReader(netconn), writer: bufio.NewWriter(netconn)}
+		return readUntilDelimiter(context.Background(), conn, opts, remoteByteDelimiter)
+	}
+
+	opts := &Options{MaxReplySize: 16, MaxReplyDepth: DefaultMaxReplyDepth}
+	if _, err := read("*3\r\n+"+strings.Repeat("x", 32)+"\r\n:5001\r\n+\r\n"+remoteByteDelimiter, opts); !errors.Is(err, ErrReplyTooLarge) {
+		t.Fatalf("Expected ErrReplyTooLarge, got %v", err)
+	}
+
+	// the delimiter inside a string value does not end the reply, even at the end of a chunk
+	reply := "*3\r\n$4\r\n" + remoteByteDelimiter + "\r\n:5001\r\n+\r\n"
+	opts.MaxReplySize = DefaultMaxReplySize
+
+	buffer, err := read(reply+remoteByteDelimiter, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if buffer.String() != reply {
+		t.Fatalf("Expected reply %q, got %q", reply, buffer.String())
+	}
+}
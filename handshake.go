@@ -0,0 +1,102 @@
+package universum
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// ServerInfo describes the server as negotiated by the HELLO handshake.
+//
+// Fields:
+// - Version: The server version.
+// - ProtocolVersion: The protocol version the server agreed to speak.
+// - Capabilities: The commands and features advertised by the server.
+type ServerInfo struct {
+	Version         string
+	ProtocolVersion int64
+	Capabilities    []string
+}
+
+// handshaker introduces every new connection to the server with HELLO,
+// announcing the client name, client ID, library version and the desired
+// protocol version, and remembers the server information of the reply.
+// Servers which predate HELLO reject the command; the connection is used
+// regardless and no server information is recorded.
+type handshaker struct {
+	opts     *Options
+	clientID string
+	info     atomic.Pointer[ServerInfo]
+}
+
+func newHandshaker(opts *Options, clientID string) *handshaker {
+	return &handshaker{opts: opts, clientID: clientID}
+}
+
+// handshake sends HELLO on a freshly dialed connection. Only socket failures
+// are returned, as they leave the connection unusable.
+func (h *handshaker) handshake(ctx context.Context, conn connInterface) error {
+	if h == nil {
+		return nil
+	}
+
+	result, err := execOnConn(ctx, conn, h.opts, newCommandTrace(), commandHello,
+		ProtocolVersion, h.opts.ClientName, h.clientID, Version())
+
+	if err != nil {
+		if isConnBroken(err) {
+			return err
+		}
+
+		// the server predates the handshake
+		h.info.Store(nil)
+		return nil
+	}
+
+	if result.code != RespHelloSuccess {
+		h.info.Store(nil)
+		return nil
+	}
+
+	h.info.Store(parseServerInfo(result.value))
+	return nil
+}
+
+// serverInfo returns the server information of the most recent handshake,
+// nil if the server does not support it
+func (h *handshaker) serverInfo() *ServerInfo {
+	if h == nil {
+		return nil
+	}
+
+	return h.info.Load()
+}
+
+// parseServerInfo reads the HELLO reply, a map holding the server version,
+// the negotiated protocol version and the list of capabilities. Unknown or
+// malformed entries are ignored.
+func parseServerInfo(value interface{}) *ServerInfo {
+	info := &ServerInfo{}
+
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return info
+	}
+
+	if version, ok := fields["version"].(string); ok {
+		info.Version = version
+	}
+
+	if protocol, ok := fields["protocol"].(int64); ok {
+		info.ProtocolVersion = protocol
+	}
+
+	if capabilities, ok := fields["capabilities"].([]interface{}); ok {
+		for _, capability := range capabilities {
+			if name, ok := capability.(string); ok {
+				info.Capabilities = append(info.Capabilities, name)
+			}
+		}
+	}
+
+	return info
+}
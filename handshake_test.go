@@ -0,0 +1,101 @@
+package universum
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestHandshake_ServerInfo(t *testing.T) {
+	var mu sync.Mutex
+	var hellos [][]interface{}
+
+	server := newFakeServerPerConn(t, func() fakeHandler {
+		return func(cmd []interface{}) interface{} {
+			if cmd[0] == commandHello {
+				mu.Lock()
+				hellos = append(hellos, cmd)
+				mu.Unlock()
+
+				return fakeReply(map[string]interface{}{
+					"version":      "1.4.2",
+					"protocol":     int64(1),
+					"capabilities": []string{"SNAPSHOT", "EXPIRE"},
+				}, RespHelloSuccess, "OK")
+			}
+			return fakeReply(nil, RespRecordNotFound, "")
+		}
+	})
+
+	client := newClientFor(t, server, func(opts *Options) {
+		opts.ClientName = "billing"
+	})
+
+	if info := client.ServerInfo(); info != nil {
+		t.Fatalf("Expected no server info before the first connection, got %+v", info)
+	}
+
+	if _, err := client.Get(context.Background(), "key"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(hellos) != 1 {
+		t.Fatalf("Expected one HELLO for the new connection, got %d", len(hellos))
+	}
+
+	expected := []interface{}{commandHello, ProtocolVersion, "billing", client.id, Version()}
+	if !reflect.DeepEqual(hellos[0], expected) {
+		t.Fatalf("Expected HELLO %v, got %v", expected, hellos[0])
+	}
+
+	info := client.ServerInfo()
+	if info == nil || info.Version != "1.4.2" || info.ProtocolVersion != 1 {
+		t.Fatalf("Expected negotiated server info, got %+v", info)
+	}
+	if !reflect.DeepEqual(info.Capabilities, []string{"SNAPSHOT", "EXPIRE"}) {
+		t.Fatalf("Expected advertised capabilities, got %v", info.Capabilities)
+	}
+}
+
+func TestHandshake_OlderServer(t *testing.T) {
+	client, err := NewClient(mockPoolOptions(t))
+	if err != nil {
+		t.Fatalf("Expected no error while creating client, got %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("Expected commands to work without the handshake, got %v", err)
+	}
+
+	if info := client.ServerInfo(); info != nil {
+		t.Fatalf("Expected no server info from a server without HELLO, got %+v", info)
+	}
+
+	if client.pool.Len() != 1 {
+		t.Fatalf("Expected the connection to be kept, got %d live", client.pool.Len())
+	}
+}
+
+func TestParseServerInfo_Malformed(t *testing.T) {
+	info := parseServerInfo(map[string]interface{}{
+		"version":      int64(3),
+		"protocol":     "one",
+		"capabilities": []interface{}{"GET", int64(1)},
+	})
+
+	if info.Version != "" || info.ProtocolVersion != 0 {
+		t.Fatalf("Expected malformed fields to be ignored, got %+v", info)
+	}
+	if !reflect.DeepEqual(info.Capabilities, []string{"GET"}) {
+		t.Fatalf("Expected only valid capabilities, got %v", info.Capabilities)
+	}
+
+	if info := parseServerInfo("OK"); info == nil || info.Version != "" {
+		t.Fatalf("Expected empty server info for a non-map reply, got %+v", info)
+	}
+}
@@ -0,0 +1,193 @@
+package universum
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// hedgeLatencySamples is the number of recent read latencies the dynamic
+// hedge delay is computed from.
+const hedgeLatencySamples = 512
+
+// hedgeMinSamples is the number of samples required before the dynamic hedge
+// delay replaces the configured HedgeDelay.
+const hedgeMinSamples = 32
+
+// hedgeRecomputeEvery is how many new samples trigger a recomputation of the
+// dynamic hedge delay.
+const hedgeRecomputeEvery = 64
+
+// hedger decides when a slow read deserves a duplicate request, keeping the
+// number of duplicates within a percentage of the hedged traffic.
+type hedger struct {
+	mu sync.Mutex
+
+	delay      time.Duration
+	percentile float64
+	maxRatio   float64
+
+	samples      []time.Duration
+	next         int
+	sinceCompute int
+	dynamicDelay time.Duration
+
+	requests int64
+	hedges   int64
+}
+
+func newHedger(opts *Options) *hedger {
+	return &hedger{
+		delay:      opts.HedgeDelay,
+		percentile: opts.HedgePercentile,
+		maxRatio:   opts.HedgeMaxPercent / 100,
+		samples:    make([]time.Duration, 0, hedgeLatencySamples),
+	}
+}
+
+// hedgeDelay returns how long to wait for a read before hedging it, or zero
+// if no delay is known yet.
+func (h *hedger) hedgeDelay() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.dynamicDelay > 0 {
+		return h.dynamicDelay
+	}
+	return h.delay
+}
+
+// begin counts a new hedgeable request.
+func (h *hedger) begin() {
+	h.mu.Lock()
+	h.requests++
+	h.mu.Unlock()
+}
+
+// allowHedge reserves a duplicate request if the budget permits it.
+func (h *hedger) allowHedge() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if float64(h.hedges+1) > float64(h.requests)*h.maxRatio {
+		return false
+	}
+
+	h.hedges++
+	return true
+}
+
+// observe records the latency of a successful read.
+func (h *hedger) observe(latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.samples) < hedgeLatencySamples {
+		h.samples = append(h.samples, latency)
+	} else {
+		h.samples[h.next] = latency
+		h.next = (h.next + 1) % hedgeLatencySamples
+	}
+
+	h.sinceCompute++
+	if h.percentile <= 0 || len(h.samples) < hedgeMinSamples {
+		return
+	}
+
+	if h.dynamicDelay == 0 || h.sinceCompute >= hedgeRecomputeEvery {
+		h.dynamicDelay = latencyPercentile(h.samples, h.percentile)
+		h.sinceCompute = 0
+	}
+}
+
+// latencyPercentile returns the given percentile (0-1) of the samples.
+func latencyPercentile(samples []time.Duration, percentile float64) time.Duration {
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	index := int(percentile*float64(len(sorted))+0.5) - 1
+	if index < 0 {
+		index = 0
+	} else if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+
+	return sorted[index]
+}
+
+type hedgeResult struct {
+	result *CommandResult
+	err    error
+}
+
+// sendHedgedCommand sends a read command and, if it has not completed after
+// the hedge delay, sends a duplicate on another pooled connection. The first
+// successful reply wins and the other request is cancelled.
+func sendHedgedCommand(ctx context.Context, c *Client, command string, args ...interface{}) (*CommandResult, error) {
+	if c.hedger == nil {
+		return sendCommand(ctx, c, command, args...)
+	}
+
+	delay := c.hedger.hedgeDelay()
+	if delay <= 0 {
+		return c.sendObserved(ctx, command, args...)
+	}
+
+	c.hedger.begin()
+
+	hedgeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult, 2)
+	launch := func() {
+		go func() {
+			result, err := c.sendObserved(hedgeCtx, command, args...)
+			results <- hedgeResult{result: result, err: err}
+		}()
+	}
+
+	launch()
+	pending := 1
+	hedged := false
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var firstErr error
+	for {
+		select {
+		case res := <-results:
+			pending--
+
+			if res.err == nil {
+				return res.result, nil
+			}
+			if firstErr == nil {
+				firstErr = res.err
+			}
+			if pending == 0 {
+				return nil, firstErr
+			}
+
+		case <-timer.C:
+			if !hedged && c.hedger.allowHedge() {
+				hedged = true
+				pending++
+				launch()
+			}
+		}
+	}
+}
+
+// sendObserved sends the command and feeds its latency to the hedger.
+func (c *Client) sendObserved(ctx context.Context, command string, args ...interface{}) (*CommandResult, error) {
+	start := time.Now()
+	result, err := sendCommand(ctx, c, command, args...)
+
+	if err == nil {
+		c.hedger.observe(time.Since(start))
+	}
+
+	return result, err
+}
@@ -0,0 +1,104 @@
+package universum
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLatencyPercentile(t *testing.T) {
+	samples := make([]time.Duration, 0, 100)
+	for i := 100; i > 0; i-- {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+
+	if p := latencyPercentile(samples, 0.95); p != 95*time.Millisecond {
+		t.Errorf("Expected p95 to be 95ms, got %s", p)
+	}
+	if p := latencyPercentile(samples, 0.5); p != 50*time.Millisecond {
+		t.Errorf("Expected p50 to be 50ms, got %s", p)
+	}
+}
+
+func TestHedger_DynamicDelayAndBudget(t *testing.T) {
+	h := newHedger(&Options{HedgeDelay: time.Second, HedgePercentile: 0.9, HedgeMaxPercent: 10})
+
+	for i := 0; i < hedgeMinSamples-1; i++ {
+		h.observe(10 * time.Millisecond)
+	}
+	if delay := h.hedgeDelay(); delay != time.Second {
+		t.Fatalf("Expected configured delay until enough samples, got %s", delay)
+	}
+
+	h.observe(10 * time.Millisecond)
+	if delay := h.hedgeDelay(); delay != 10*time.Millisecond {
+		t.Fatalf("Expected dynamic delay of 10ms, got %s", delay)
+	}
+
+	for i := 0; i < 20; i++ {
+		h.begin()
+	}
+	if !h.allowHedge() || !h.allowHedge() || h.allowHedge() {
+		t.Fatal("Expected exactly two hedges to fit a 10% budget of 20 requests")
+	}
+}
+
+func TestClient_HedgedGet(t *testing.T) {
+	var calls int64
+
+	client := newFakeClient(t, func(cmd []interface{}) interface{} {
+		if atomic.AddInt64(&calls, 1) == 1 {
+			time.Sleep(500 * time.Millisecond)
+		}
+		return fakeReply(map[string]interface{}{"Value": "v"}, RespRecordFound, "")
+	}, func(opts *Options) {
+		opts.HedgeDelay = 20 * time.Millisecond
+		opts.HedgeMaxPercent = 100
+	})
+
+	start := time.Now()
+	result, err := client.Get(context.Background(), "key")
+	elapsed := time.Since(start)
+
+	if err != nil || result.Value != "v" {
+		t.Fatalf("Expected hedged GET to succeed, got %v, %v", result, err)
+	}
+	if elapsed > 250*time.Millisecond {
+		t.Fatalf("Expected the hedge to answer before the slow request, took %s", elapsed)
+	}
+	if atomic.LoadInt64(&calls) != 2 {
+		t.Fatalf("Expected 2 requests to reach the server, got %d", atomic.LoadInt64(&calls))
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for client.pool.Len() != 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if client.pool.Len() != 1 {
+		t.Fatalf("Expected the cancelled loser connection to be discarded, got %d live", client.pool.Len())
+	}
+}
+
+func TestClient_HedgeBudget(t *testing.T) {
+	var calls int64
+
+	client := newFakeClient(t, func(cmd []interface{}) interface{} {
+		atomic.AddInt64(&calls, 1)
+		time.Sleep(30 * time.Millisecond)
+		return fakeReply(map[string]interface{}{"Value": "v"}, RespRecordFound, "")
+	}, func(opts *Options) {
+		opts.HedgeDelay = time.Millisecond
+		opts.HedgeMaxPercent = 1
+	})
+
+	for i := 0; i < 10; i++ {
+		if _, err := client.Get(context.Background(), "key"); err != nil {
+			t.Fatalf("Expected GET to succeed, got %v", err)
+		}
+	}
+
+	if atomic.LoadInt64(&calls) != 10 {
+		t.Fatalf("Expected no hedges within a 1%% budget of 10 requests, got %d requests", atomic.LoadInt64(&calls))
+	}
+}
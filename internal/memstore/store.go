@@ -0,0 +1,186 @@
+// Package memstore implements the in-memory keyspace shared by the test
+// server and the mock client, with the semantics of UniversumDB.
+package memstore
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cshekharsharma/universum-client-go"
+)
+
+// record is a stored value with its optional expiry time
+type record struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+// AppendNotFound is the content length replied when appending to a missing key
+const AppendNotFound int64 = -99999999
+
+// Clock tells the store the current time, which decides when keys expire.
+type Clock interface {
+	Now() time.Time
+}
+
+// Store is an in-memory keyspace. Expired records are removed lazily when
+// they are accessed.
+type Store struct {
+	clock Clock
+
+	mu      sync.Mutex
+	records map[string]*record
+}
+
+// New returns an empty store expiring keys according to clock.
+func New(clock Clock) *Store {
+	return &Store{clock: clock, records: make(map[string]*record)}
+}
+
+// lookup returns the live record of the key, deleting it if it has expired.
+// The caller must hold the lock.
+func (s *Store) lookup(key string) (*record, bool) {
+	rec, ok := s.records[key]
+	if !ok {
+		return nil, false
+	}
+
+	if !rec.expiresAt.IsZero() && !s.clock.Now().Before(rec.expiresAt) {
+		delete(s.records, key)
+		return nil, false
+	}
+
+	return rec, true
+}
+
+// expiry converts a TTL in seconds to an expiry time, zero meaning no expiry
+func (s *Store) expiry(ttl int64) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+
+	return s.clock.Now().Add(time.Duration(ttl) * time.Second)
+}
+
+// Get returns the value of the key and whether it exists
+func (s *Store) Get(key string) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.lookup(key)
+	if !ok {
+		return nil, false
+	}
+
+	return rec.value, true
+}
+
+// Set stores the value with a time-to-live in seconds, zero meaning no expiry
+func (s *Store) Set(key string, value interface{}, ttl int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[key] = &record{value: value, expiresAt: s.expiry(ttl)}
+}
+
+// Delete removes the key and reports whether it existed
+func (s *Store) Delete(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.lookup(key); !ok {
+		return false
+	}
+
+	delete(s.records, key)
+	return true
+}
+
+// Increment adds offset to an integer value, creating missing keys with the
+// offset as their value. It fails if the value is not an integer.
+func (s *Store) Increment(key string, offset int64) (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.lookup(key)
+	if !ok {
+		s.records[key] = &record{value: offset}
+		return offset, true
+	}
+
+	current, ok := rec.value.(int64)
+	if !ok {
+		return 0, false
+	}
+
+	rec.value = current + offset
+	return current + offset, true
+}
+
+// Append appends suffix to a string value and returns the new length with
+// the response code
+func (s *Store) Append(key, suffix string) (int64, int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.lookup(key)
+	if !ok {
+		return AppendNotFound, universum.RespRecordNotFound
+	}
+
+	current, ok := rec.value.(string)
+	if !ok {
+		return AppendNotFound, universum.RespIinvalidDatatype
+	}
+
+	rec.value = current + suffix
+	return int64(len(current) + len(suffix)), universum.RespRecordUpdated
+}
+
+// TTL returns the remaining time-to-live of the key in whole seconds,
+// rounded up, or -1 if the key does not expire
+func (s *Store) TTL(key string) (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.lookup(key)
+	if !ok {
+		return 0, false
+	}
+
+	if rec.expiresAt.IsZero() {
+		return -1, true
+	}
+
+	remaining := rec.expiresAt.Sub(s.clock.Now())
+	return int64((remaining + time.Second - 1) / time.Second), true
+}
+
+// Expire sets the time-to-live of the key in seconds, zero removing the expiry
+func (s *Store) Expire(key string, ttl int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.lookup(key)
+	if !ok {
+		return false
+	}
+
+	rec.expiresAt = s.expiry(ttl)
+	return true
+}
+
+// Len returns the number of live keys
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for key := range s.records {
+		if _, ok := s.lookup(key); ok {
+			count++
+		}
+	}
+
+	return count
+}
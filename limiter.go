@@ -0,0 +1,274 @@
+package universum
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CommandClass groups commands which share a rate limit.
+type CommandClass int
+
+const (
+	// CommandClassRead covers the commands which only read keys.
+	CommandClassRead CommandClass = iota
+	// CommandClassWrite covers the commands which modify keys.
+	CommandClassWrite
+	// CommandClassAdmin covers server-wide commands such as SNAPSHOT and INFO.
+	CommandClassAdmin
+)
+
+// String returns the name of the command class.
+func (cc CommandClass) String() string {
+	switch cc {
+	case CommandClassRead:
+		return "read"
+	case CommandClassWrite:
+		return "write"
+	case CommandClassAdmin:
+		return "admin"
+	default:
+		return fmt.Sprintf("CommandClass(%d)", int(cc))
+	}
+}
+
+// commandClassOf returns the class the command is rate limited under.
+func commandClassOf(command string) CommandClass {
+	switch command {
+	case commandSet, commandDelete, commandIncr, commandDecr, commandAppend,
+		commandMset, commandMdelete, commandExpire:
+		return CommandClassWrite
+
+	case commandSnapshot, commandInfo, commandHelp:
+		return CommandClassAdmin
+
+	default:
+		return CommandClassRead
+	}
+}
+
+// tokenBucket is a token-bucket rate limiter refilled continuously at rate
+// tokens per second, holding at most burst tokens.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+func newTokenBucket(rate float64, burst int64) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+		now:    time.Now,
+	}
+}
+
+// reserve takes a token, possibly borrowing against future refills, and
+// returns how long the caller has to wait before the token is available.
+// It must be called with mu held.
+func (tb *tokenBucket) reserve() time.Duration {
+	now := tb.now()
+	if now.After(tb.last) {
+		tb.tokens += now.Sub(tb.last).Seconds() * tb.rate
+		if tb.tokens > tb.burst {
+			tb.tokens = tb.burst
+		}
+		tb.last = now
+	}
+
+	tb.tokens--
+	if tb.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-tb.tokens / tb.rate * float64(time.Second))
+}
+
+// cancelReservation returns a token taken by reserve which was not used.
+func (tb *tokenBucket) cancelReservation() {
+	tb.mu.Lock()
+	tb.tokens++
+	if tb.tokens > tb.burst {
+		tb.tokens = tb.burst
+	}
+	tb.mu.Unlock()
+}
+
+// wait blocks until a token is available. With failFast set, or when the
+// wait would outlast the context deadline, it fails with ErrRateLimited
+// without consuming a token.
+func (tb *tokenBucket) wait(ctx context.Context, failFast bool) error {
+	tb.mu.Lock()
+	delay := tb.reserve()
+	tb.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+
+	if failFast {
+		tb.cancelReservation()
+		return fmt.Errorf("rate limit exceeded: %w", ErrRateLimited)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		tb.cancelReservation()
+		return fmt.Errorf("rate limit wait of %s exceeds the context deadline: %w", delay, ErrRateLimited)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		tb.cancelReservation()
+		return ctx.Err()
+	}
+}
+
+// concurrencyLimiter bounds the number of commands in flight, queueing the
+// callers beyond the limit in FIFO order.
+type concurrencyLimiter struct {
+	mu       sync.Mutex
+	limit    int64
+	inFlight int64
+	waiters  []chan struct{}
+}
+
+func newConcurrencyLimiter(limit int64) *concurrencyLimiter {
+	return &concurrencyLimiter{limit: limit}
+}
+
+// acquire claims an in-flight slot, blocking until one is free unless
+// failFast is set, in which case it fails with ErrTooManyInFlight.
+func (cl *concurrencyLimiter) acquire(ctx context.Context, failFast bool) error {
+	cl.mu.Lock()
+	if cl.inFlight < cl.limit && len(cl.waiters) == 0 {
+		cl.inFlight++
+		cl.mu.Unlock()
+		return nil
+	}
+
+	if failFast {
+		cl.mu.Unlock()
+		return fmt.Errorf("%d commands already in flight: %w", cl.limit, ErrTooManyInFlight)
+	}
+
+	ready := make(chan struct{})
+	cl.waiters = append(cl.waiters, ready)
+	cl.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+
+	case <-ctx.Done():
+		cl.mu.Lock()
+		defer cl.mu.Unlock()
+
+		for i, waiter := range cl.waiters {
+			if waiter == ready {
+				cl.waiters = append(cl.waiters[:i], cl.waiters[i+1:]...)
+				return ctx.Err()
+			}
+		}
+
+		// The slot was handed over concurrently with the cancellation, pass it on.
+		cl.inFlight--
+		cl.wakeWaiters()
+		return ctx.Err()
+	}
+}
+
+// release frees a slot claimed by acquire.
+func (cl *concurrencyLimiter) release() {
+	cl.mu.Lock()
+	cl.inFlight--
+	cl.wakeWaiters()
+	cl.mu.Unlock()
+}
+
+// setLimit changes the number of slots, waking queued callers if it grew.
+func (cl *concurrencyLimiter) setLimit(limit int64) {
+	cl.mu.Lock()
+	cl.limit = limit
+	cl.wakeWaiters()
+	cl.mu.Unlock()
+}
+
+// wakeWaiters hands free slots to queued callers, it must be called with mu held.
+func (cl *concurrencyLimiter) wakeWaiters() {
+	for len(cl.waiters) > 0 && cl.inFlight < cl.limit {
+		ready := cl.waiters[0]
+		cl.waiters = cl.waiters[1:]
+		cl.inFlight++
+		close(ready)
+	}
+}
+
+// commandLimiter applies the per-class rate limits and the in-flight limit
+// configured on Options to every command sent by a client.
+type commandLimiter struct {
+	buckets  map[CommandClass]*tokenBucket
+	inFlight *concurrencyLimiter
+	failFast bool
+}
+
+// newCommandLimiter returns nil if no limit is configured.
+func newCommandLimiter(opts *Options) *commandLimiter {
+	limiter := &commandLimiter{
+		buckets:  make(map[CommandClass]*tokenBucket),
+		failFast: opts.LimiterFailFast,
+	}
+
+	if opts.ReadRateLimit > 0 {
+		limiter.buckets[CommandClassRead] = newTokenBucket(opts.ReadRateLimit, opts.ReadRateBurst)
+	}
+	if opts.WriteRateLimit > 0 {
+		limiter.buckets[CommandClassWrite] = newTokenBucket(opts.WriteRateLimit, opts.WriteRateBurst)
+	}
+	if opts.AdminRateLimit > 0 {
+		limiter.buckets[CommandClassAdmin] = newTokenBucket(opts.AdminRateLimit, opts.AdminRateBurst)
+	}
+	if opts.MaxInFlight > 0 {
+		limiter.inFlight = newConcurrencyLimiter(opts.MaxInFlight)
+	}
+
+	if len(limiter.buckets) == 0 && limiter.inFlight == nil {
+		return nil
+	}
+
+	return limiter
+}
+
+// acquire waits for the command's rate limit and an in-flight slot. The
+// returned function must be called once the command has completed.
+func (cl *commandLimiter) acquire(ctx context.Context, command string) (func(), error) {
+	if bucket, ok := cl.buckets[commandClassOf(command)]; ok {
+		if err := bucket.wait(ctx, cl.failFast); err != nil {
+			return nil, err
+		}
+	}
+
+	if cl.inFlight == nil {
+		return func() {}, nil
+	}
+
+	if err := cl.inFlight.acquire(ctx, cl.failFast); err != nil {
+		return nil, err
+	}
+
+	return cl.inFlight.release, nil
+}
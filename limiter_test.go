@@ -0,0 +1,131 @@
+package universum
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCommandClassOf(t *testing.T) {
+	testCases := map[string]CommandClass{
+		commandGet:      CommandClassRead,
+		commandMget:     CommandClassRead,
+		commandPing:     CommandClassRead,
+		commandSet:      CommandClassWrite,
+		commandMdelete:  CommandClassWrite,
+		commandExpire:   CommandClassWrite,
+		commandSnapshot: CommandClassAdmin,
+		commandInfo:     CommandClassAdmin,
+	}
+
+	for command, expected := range testCases {
+		if class := commandClassOf(command); class != expected {
+			t.Errorf("Expected %s to be a %s command, got %s", command, expected, class)
+		}
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	clock := time.Now()
+	bucket := newTokenBucket(10, 2)
+	bucket.now = func() time.Time { return clock }
+	bucket.last = clock
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := bucket.wait(ctx, true); err != nil {
+			t.Fatalf("Expected burst token %d to be available, got %v", i, err)
+		}
+	}
+
+	if err := bucket.wait(ctx, true); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected rate limited error once the burst is spent, got %v", err)
+	}
+
+	clock = clock.Add(100 * time.Millisecond)
+	if err := bucket.wait(ctx, true); err != nil {
+		t.Fatalf("Expected token to be refilled after 100ms, got %v", err)
+	}
+
+	deadlineCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := bucket.wait(deadlineCtx, false); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected wait beyond the context deadline to fail, got %v", err)
+	}
+
+	bucket.now = time.Now
+	bucket.last = time.Now()
+	start := time.Now()
+	if err := bucket.wait(ctx, false); err != nil {
+		t.Fatalf("Expected blocking wait to succeed, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("Expected blocking wait for the next token, took %s", elapsed)
+	}
+}
+
+func TestConcurrencyLimiter(t *testing.T) {
+	limiter := newConcurrencyLimiter(2)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := limiter.acquire(ctx, false); err != nil {
+			t.Fatalf("Expected slot %d to be available, got %v", i, err)
+		}
+	}
+
+	if err := limiter.acquire(ctx, true); !errors.Is(err, ErrTooManyInFlight) {
+		t.Fatalf("Expected too many in flight error, got %v", err)
+	}
+
+	cancelCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := limiter.acquire(cancelCtx, false); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected blocked acquire to respect the context, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := limiter.acquire(ctx, false); err != nil {
+			t.Errorf("Expected queued acquire to succeed, got %v", err)
+		}
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	limiter.release()
+	wg.Wait()
+
+	if limiter.inFlight != 2 || len(limiter.waiters) != 0 {
+		t.Fatalf("Expected slot to be handed over, got %d in flight and %d waiters", limiter.inFlight, len(limiter.waiters))
+	}
+}
+
+func TestClient_RateLimit(t *testing.T) {
+	client := newFakeClient(t, func(cmd []interface{}) interface{} {
+		if cmd[0] == commandSet {
+			return fakeReply(true, RespRecordUpdated, "")
+		}
+		return fakeReply(nil, RespRecordNotFound, "")
+	}, func(opts *Options) {
+		opts.WriteRateLimit = 1
+		opts.WriteRateBurst = 1
+		opts.LimiterFailFast = true
+	})
+
+	ctx := context.Background()
+	if _, err := client.Set(ctx, "key", "value", 0); err != nil {
+		t.Fatalf("Expected first write to pass, got %v", err)
+	}
+	if _, err := client.Set(ctx, "key", "value", 0); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected second write to be rate limited, got %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := client.Get(ctx, "key"); err != nil {
+			t.Fatalf("Expected reads to be unaffected by the write limit, got %v", err)
+		}
+	}
+}
@@ -1,6 +1,12 @@
 package universum
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"math"
+	"net"
 	"time"
 )
 
@@ -10,6 +16,9 @@ const DefaultClientName = "GoClient"
 const DefaultDialTimeout = 1 * time.Second
 const MaxDialTimeout = 5 * time.Second
 
+const DefaultConnWaitTimeout = 1 * time.Second
+const MaxConnWaitTimeout = 1 * time.Minute
+
 const DefaultReadTimeout = 1 * time.Second
 const MaxReadTimeout = 3 * time.Second
 
@@ -28,27 +37,131 @@ const MaxConnPoolsize = 1 << 16    // 65636
 const DefaultConnMaxLifetime = 10 * time.Minute
 const MaxConnMaxLifetime = 30 * time.Minute
 
+const MaxConnMaxIdleTime = 30 * time.Minute
+
+const DefaultConnReapInterval = 1 * time.Minute
+const MaxConnReapInterval = 10 * time.Minute
+
+const DefaultHedgeMaxPercent = 10.0
+const MaxHedgeMaxPercent = 100.0
+
+const DefaultShutdownRedialDelay = 1 * time.Second
+const MaxShutdownRedialDelay = 1 * time.Minute
+
+const DefaultBreakerConsecutiveFailures = 5
+const DefaultBreakerFailureRate = 0.5
+const DefaultBreakerMinRequests = 20
+const DefaultBreakerWindow = 10 * time.Second
+const DefaultBreakerCooldown = 5 * time.Second
+const MaxBreakerCooldown = 5 * time.Minute
+
+const DefaultTLSMinVersion = tls.VersionTLS12
+
+const DefaultTLSReloadInterval = 30 * time.Second
+const MinTLSReloadInterval = 100 * time.Millisecond
+
+// MinTimeout is the smallest dial, wait, read or write timeout accepted by
+// Validate. Smaller values are most likely a number of seconds written before
+// the timeouts became time.Duration values.
+const MinTimeout = 1 * time.Millisecond
+
+const DefaultCredentialsRefreshInterval = 1 * time.Minute
+
+const DefaultBufferSize = 1 << 12 // 4096
+const MaxBufferSize = 1 << 20     // 1MiB
+
+const DefaultMaxReplySize = 1 << 26 // 64MiB
+const AllowedMaxReplySize = 1 << 30 // 1GiB
+
+const DefaultMaxReplyDepth = 1 << 6  // 64
+const AllowedMaxReplyDepth = 1 << 10 // 1024
+
+const DefaultSlowLogMaxLen = 1 << 7 // 128
+const MaxSlowLogMaxLen = 1 << 12    // 4096
+
 type Options struct {
 	HostAddr   string
 	ClientName string
+	Username   string
+	Password   string
+	Token      string
+
+	CredentialsProvider        CredentialsProvider
+	CredentialsRefreshInterval time.Duration
 
 	DialTimeout     time.Duration
 	ConnWaitTimeout time.Duration
 	ReadTimeout     time.Duration
 	WriteTimeout    time.Duration
 
+	Dialer            func(ctx context.Context, network, addr string) (net.Conn, error)
+	KeepAlive         time.Duration
+	DisableTCPNoDelay bool
+	ReadBufferSize    int
+	WriteBufferSize   int
+	MaxReplySize      int64
+	MaxReplyDepth     int64
+	Recorder          *Recorder
+
 	MaxRetries   int64
 	RetryBackoff time.Duration
 
-	ConnPoolsize    int64
-	ConnMaxLifetime time.Duration
-	IsReadonly      bool
+	StrictValidation bool
+
+	ConnPoolsize     int64
+	ConnMaxLifetime  time.Duration
+	ConnMaxIdleTime  time.Duration
+	ConnReapInterval time.Duration
+	MinIdleConns     int64
+	IsReadonly       bool
+
+	WarmupConns    int64
+	WarmupPing     bool
+	WarmupRequired bool
 
 	EnableTLS          bool
 	TLSCertFile        string
 	TLSKeyFile         string
 	CAFile             string
 	InsecureSkipVerify bool
+	TLSServerName      string
+	TLSMinVersion      uint16
+	TLSCipherSuites    []uint16
+
+	TLSConfig            *tls.Config
+	TLSCertPEM           []byte
+	TLSKeyPEM            []byte
+	CAPEM                []byte
+	GetClientCertificate func(*tls.CertificateRequestInfo) (*tls.Certificate, error)
+	TLSReloadInterval    time.Duration
+
+	ReadRateLimit   float64
+	ReadRateBurst   int64
+	WriteRateLimit  float64
+	WriteRateBurst  int64
+	AdminRateLimit  float64
+	AdminRateBurst  int64
+	MaxInFlight     int64
+	LimiterFailFast bool
+
+	HedgeDelay      time.Duration
+	HedgePercentile float64
+	HedgeMaxPercent float64
+
+	AdaptiveBackpressure bool
+	ShutdownRedialDelay  time.Duration
+
+	BreakerEnabled             bool
+	BreakerConsecutiveFailures int64
+	BreakerFailureRate         float64
+	BreakerMinRequests         int64
+	BreakerWindow              time.Duration
+	BreakerCooldown            time.Duration
+	OnBreakerStateChange       func(from, to BreakerState)
+
+	SlowLogThreshold time.Duration
+	SlowLogMaxLen    int64
+	OnSlowCommand    func(entry SlowLogEntry)
 }
 
 func (opts *Options) Init() {
@@ -69,6 +182,13 @@ func (opts *Options) Init() {
 		opts.DialTimeout = MaxDialTimeout
 	}
 
+	// ConnWaitTimeout validation
+	if opts.ConnWaitTimeout <= 0 {
+		opts.ConnWaitTimeout = DefaultConnWaitTimeout
+	} else if opts.ConnWaitTimeout > MaxConnWaitTimeout {
+		opts.ConnWaitTimeout = MaxConnWaitTimeout
+	}
+
 	// ReadTimeout validation
 	if opts.ReadTimeout <= 0 {
 		opts.ReadTimeout = DefaultReadTimeout
@@ -97,6 +217,34 @@ func (opts *Options) Init() {
 		opts.RetryBackoff = MaxRetryBackoff
 	}
 
+	// ReadBufferSize validation
+	if opts.ReadBufferSize <= 0 {
+		opts.ReadBufferSize = DefaultBufferSize
+	} else if opts.ReadBufferSize > MaxBufferSize {
+		opts.ReadBufferSize = MaxBufferSize
+	}
+
+	// WriteBufferSize validation
+	if opts.WriteBufferSize <= 0 {
+		opts.WriteBufferSize = DefaultBufferSize
+	} else if opts.WriteBufferSize > MaxBufferSize {
+		opts.WriteBufferSize = MaxBufferSize
+	}
+
+	// MaxReplySize validation
+	if opts.MaxReplySize <= 0 {
+		opts.MaxReplySize = DefaultMaxReplySize
+	} else if opts.MaxReplySize > AllowedMaxReplySize {
+		opts.MaxReplySize = AllowedMaxReplySize
+	}
+
+	// MaxReplyDepth validation
+	if opts.MaxReplyDepth <= 0 {
+		opts.MaxReplyDepth = DefaultMaxReplyDepth
+	} else if opts.MaxReplyDepth > AllowedMaxReplyDepth {
+		opts.MaxReplyDepth = AllowedMaxReplyDepth
+	}
+
 	// ConnPoolsize validation
 	if opts.ConnPoolsize <= 0 {
 		opts.ConnPoolsize = DefaultConnPoolsize
@@ -110,4 +258,297 @@ func (opts *Options) Init() {
 	} else if opts.ConnMaxLifetime > MaxConnMaxLifetime {
 		opts.ConnMaxLifetime = MaxConnMaxLifetime
 	}
+
+	// ConnMaxIdleTime validation, zero or negative values disable the idle timeout
+	if opts.ConnMaxIdleTime < 0 {
+		opts.ConnMaxIdleTime = 0
+	} else if opts.ConnMaxIdleTime > MaxConnMaxIdleTime {
+		opts.ConnMaxIdleTime = MaxConnMaxIdleTime
+	}
+
+	// MinIdleConns validation
+	if opts.MinIdleConns < 0 {
+		opts.MinIdleConns = 0
+	} else if opts.MinIdleConns > opts.ConnPoolsize {
+		opts.MinIdleConns = opts.ConnPoolsize
+	}
+
+	// ConnReapInterval validation, the reaper only runs if there is an idle
+	// timeout to enforce or idle connections to keep
+	if opts.ConnMaxIdleTime == 0 && opts.MinIdleConns == 0 {
+		opts.ConnReapInterval = 0
+	} else if opts.ConnReapInterval <= 0 {
+		opts.ConnReapInterval = DefaultConnReapInterval
+	} else if opts.ConnReapInterval > MaxConnReapInterval {
+		opts.ConnReapInterval = MaxConnReapInterval
+	}
+
+	// WarmupConns validation
+	if opts.WarmupConns < 0 {
+		opts.WarmupConns = 0
+	} else if opts.WarmupConns > opts.ConnPoolsize {
+		opts.WarmupConns = opts.ConnPoolsize
+	}
+
+	// CredentialsRefreshInterval validation, negative values disable the refresh
+	if opts.CredentialsRefreshInterval == 0 {
+		opts.CredentialsRefreshInterval = DefaultCredentialsRefreshInterval
+	}
+
+	// TLSReloadInterval validation, negative values disable the reload
+	if opts.TLSReloadInterval == 0 {
+		opts.TLSReloadInterval = DefaultTLSReloadInterval
+	} else if opts.TLSReloadInterval > 0 && opts.TLSReloadInterval < MinTLSReloadInterval {
+		opts.TLSReloadInterval = MinTLSReloadInterval
+	}
+
+	// Rate limit validation
+	if opts.ReadRateLimit > 0 && opts.ReadRateBurst <= 0 {
+		opts.ReadRateBurst = int64(math.Ceil(opts.ReadRateLimit))
+	}
+
+	if opts.WriteRateLimit > 0 && opts.WriteRateBurst <= 0 {
+		opts.WriteRateBurst = int64(math.Ceil(opts.WriteRateLimit))
+	}
+
+	if opts.AdminRateLimit > 0 && opts.AdminRateBurst <= 0 {
+		opts.AdminRateBurst = int64(math.Ceil(opts.AdminRateLimit))
+	}
+
+	if opts.MaxInFlight < 0 {
+		opts.MaxInFlight = 0
+	}
+
+	// Hedging validation
+	if opts.HedgeDelay < 0 {
+		opts.HedgeDelay = 0
+	}
+
+	if opts.HedgePercentile < 0 || opts.HedgePercentile >= 1 {
+		opts.HedgePercentile = 0
+	}
+
+	if opts.HedgeMaxPercent <= 0 {
+		opts.HedgeMaxPercent = DefaultHedgeMaxPercent
+	} else if opts.HedgeMaxPercent > MaxHedgeMaxPercent {
+		opts.HedgeMaxPercent = MaxHedgeMaxPercent
+	}
+
+	// ShutdownRedialDelay validation
+	if opts.ShutdownRedialDelay <= 0 {
+		opts.ShutdownRedialDelay = DefaultShutdownRedialDelay
+	} else if opts.ShutdownRedialDelay > MaxShutdownRedialDelay {
+		opts.ShutdownRedialDelay = MaxShutdownRedialDelay
+	}
+
+	// Circuit breaker validation
+	if opts.BreakerConsecutiveFailures <= 0 {
+		opts.BreakerConsecutiveFailures = DefaultBreakerConsecutiveFailures
+	}
+
+	if opts.BreakerFailureRate <= 0 || opts.BreakerFailureRate > 1 {
+		opts.BreakerFailureRate = DefaultBreakerFailureRate
+	}
+
+	if opts.BreakerMinRequests <= 0 {
+		opts.BreakerMinRequests = DefaultBreakerMinRequests
+	}
+
+	if opts.BreakerWindow <= 0 {
+		opts.BreakerWindow = DefaultBreakerWindow
+	}
+
+	if opts.BreakerCooldown <= 0 {
+		opts.BreakerCooldown = DefaultBreakerCooldown
+	} else if opts.BreakerCooldown > MaxBreakerCooldown {
+		opts.BreakerCooldown = MaxBreakerCooldown
+	}
+
+	// SlowLogMaxLen validation
+	if opts.SlowLogMaxLen <= 0 {
+		opts.SlowLogMaxLen = DefaultSlowLogMaxLen
+	} else if opts.SlowLogMaxLen > MaxSlowLogMaxLen {
+		opts.SlowLogMaxLen = MaxSlowLogMaxLen
+	}
+}
+
+// OptionError reports an invalid field of Options. Err describes the problem
+// and wraps ErrInvalidOption.
+type OptionError struct {
+	Field string
+	Err   error
+}
+
+func (e *OptionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *OptionError) Unwrap() error {
+	return e.Err
+}
+
+// optionError builds an OptionError for the field, formatting Err like fmt.Errorf
+func optionError(field string, format string, args ...interface{}) error {
+	return &OptionError{Field: field, Err: fmt.Errorf(format, args...)}
+}
+
+// Validate reports every field which Init would reject or silently change, without
+// modifying the options. Zero values are valid and mean "use the default"; negative
+// values and values above the allowed maximum are reported. Every problem is an
+// *OptionError naming the field and wrapping ErrInvalidOption, and the problems are
+// combined with errors.Join.
+//
+// Returns:
+// - error: nil if the options are valid, otherwise an error describing every invalid field.
+func (opts *Options) Validate() error {
+	var errs []error
+
+	if err := validateHostAddr(opts.HostAddr); err != nil {
+		errs = append(errs, err)
+	}
+
+	poolsize := opts.ConnPoolsize
+	if poolsize <= 0 {
+		poolsize = DefaultConnPoolsize
+	} else if poolsize > MaxConnPoolsize {
+		poolsize = MaxConnPoolsize
+	}
+
+	errs = append(errs,
+		validateTimeout("DialTimeout", opts.DialTimeout, MaxDialTimeout),
+		validateTimeout("ConnWaitTimeout", opts.ConnWaitTimeout, MaxConnWaitTimeout),
+		validateTimeout("ReadTimeout", opts.ReadTimeout, MaxReadTimeout),
+		validateTimeout("WriteTimeout", opts.WriteTimeout, MaxWriteTimeout),
+		validateInt("ReadBufferSize", int64(opts.ReadBufferSize), MaxBufferSize),
+		validateInt("WriteBufferSize", int64(opts.WriteBufferSize), MaxBufferSize),
+		validateInt("MaxReplySize", opts.MaxReplySize, AllowedMaxReplySize),
+		validateInt("MaxReplyDepth", opts.MaxReplyDepth, AllowedMaxReplyDepth),
+		validateInt("MaxRetries", opts.MaxRetries, AllowedMaxRetries),
+		validateDuration("RetryBackoff", opts.RetryBackoff, MaxRetryBackoff),
+		validateInt("ConnPoolsize", opts.ConnPoolsize, MaxConnPoolsize),
+		validateDuration("ConnMaxLifetime", opts.ConnMaxLifetime, MaxConnMaxLifetime),
+		validateDuration("ConnMaxIdleTime", opts.ConnMaxIdleTime, MaxConnMaxIdleTime),
+		validateDuration("ConnReapInterval", opts.ConnReapInterval, MaxConnReapInterval),
+		validateInt("MinIdleConns", opts.MinIdleConns, poolsize),
+		validateInt("WarmupConns", opts.WarmupConns, poolsize),
+		validateFloat("ReadRateLimit", opts.ReadRateLimit, math.Inf(1)),
+		validateInt("ReadRateBurst", opts.ReadRateBurst, math.MaxInt64),
+		validateFloat("WriteRateLimit", opts.WriteRateLimit, math.Inf(1)),
+		validateInt("WriteRateBurst", opts.WriteRateBurst, math.MaxInt64),
+		validateFloat("AdminRateLimit", opts.AdminRateLimit, math.Inf(1)),
+		validateInt("AdminRateBurst", opts.AdminRateBurst, math.MaxInt64),
+		validateInt("MaxInFlight", opts.MaxInFlight, math.MaxInt64),
+		validateDuration("HedgeDelay", opts.HedgeDelay, math.MaxInt64),
+		validateFloat("HedgeMaxPercent", opts.HedgeMaxPercent, MaxHedgeMaxPercent),
+		validateDuration("ShutdownRedialDelay", opts.ShutdownRedialDelay, MaxShutdownRedialDelay),
+		validateInt("BreakerConsecutiveFailures", opts.BreakerConsecutiveFailures, math.MaxInt64),
+		validateFloat("BreakerFailureRate", opts.BreakerFailureRate, 1),
+		validateInt("BreakerMinRequests", opts.BreakerMinRequests, math.MaxInt64),
+		validateDuration("BreakerWindow", opts.BreakerWindow, math.MaxInt64),
+		validateDuration("BreakerCooldown", opts.BreakerCooldown, MaxBreakerCooldown),
+		validateDuration("SlowLogThreshold", opts.SlowLogThreshold, math.MaxInt64),
+		validateInt("SlowLogMaxLen", opts.SlowLogMaxLen, MaxSlowLogMaxLen),
+	)
+
+	if opts.HedgePercentile < 0 || opts.HedgePercentile >= 1 {
+		errs = append(errs, optionError("HedgePercentile", "HedgePercentile %v must be within [0, 1) and would be disabled: %w",
+			opts.HedgePercentile, ErrInvalidOption))
+	}
+
+	if opts.Token != "" && (opts.Username != "" || opts.Password != "") {
+		errs = append(errs, optionError("Token", "Token cannot be combined with Username and Password: %w", ErrInvalidOption))
+	}
+
+	if (opts.TLSCertFile == "") != (opts.TLSKeyFile == "") {
+		errs = append(errs, optionError("TLSCertFile", "TLSCertFile and TLSKeyFile must be set together: %w", ErrInvalidOption))
+	}
+
+	if (len(opts.TLSCertPEM) == 0) != (len(opts.TLSKeyPEM) == 0) {
+		errs = append(errs, optionError("TLSCertPEM", "TLSCertPEM and TLSKeyPEM must be set together: %w", ErrInvalidOption))
+	}
+
+	if opts.TLSMinVersion != 0 && formatTLSVersion(opts.TLSMinVersion) == "" {
+		errs = append(errs, optionError("TLSMinVersion", "TLSMinVersion %#04x is not a known TLS version: %w", opts.TLSMinVersion, ErrInvalidOption))
+	}
+
+	if err := validateCipherSuites(opts.TLSCipherSuites); err != nil {
+		errs = append(errs, err)
+	}
+
+	if opts.TLSReloadInterval > 0 && opts.TLSReloadInterval < MinTLSReloadInterval {
+		errs = append(errs, optionError("TLSReloadInterval", "TLSReloadInterval %v is below the minimum %v and would be raised: %w",
+			opts.TLSReloadInterval, MinTLSReloadInterval, ErrInvalidOption))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateHostAddr checks that the address is either host:port or a unix socket path
+func validateHostAddr(addr string) error {
+	if addr == "" {
+		return nil
+	}
+
+	if network, address := dialTarget(addr); network == unixDialer {
+		if address == "" {
+			return optionError("HostAddr", "HostAddr %q is missing the socket path: %w", addr, ErrInvalidOption)
+		}
+		return nil
+	}
+
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return optionError("HostAddr", "HostAddr %q is not a host:port address [%v]: %w", addr, err, ErrInvalidOption)
+	}
+
+	return nil
+}
+
+// validateDuration reports negative durations and durations Init would clamp to limit
+func validateDuration(name string, value time.Duration, limit time.Duration) error {
+	if value < 0 {
+		return optionError(name, "%s %v must not be negative: %w", name, value, ErrInvalidOption)
+	}
+
+	if value > limit {
+		return optionError(name, "%s %v exceeds the maximum %v and would be clamped: %w", name, value, limit, ErrInvalidOption)
+	}
+
+	return nil
+}
+
+// validateTimeout reports the durations rejected by validateDuration, and
+// timeouts below MinTimeout
+func validateTimeout(name string, value time.Duration, limit time.Duration) error {
+	if value > 0 && value < MinTimeout {
+		return optionError(name, "%s %v is below the minimum %v, timeouts are time.Duration values rather than seconds, "+
+			"e.g. %d * time.Second: %w", name, value, MinTimeout, int64(value), ErrInvalidOption)
+	}
+
+	return validateDuration(name, value, limit)
+}
+
+// validateInt reports negative values and values Init would clamp to limit
+func validateInt(name string, value int64, limit int64) error {
+	if value < 0 {
+		return optionError(name, "%s %d must not be negative: %w", name, value, ErrInvalidOption)
+	}
+
+	if value > limit {
+		return optionError(name, "%s %d exceeds the maximum %d and would be clamped: %w", name, value, limit, ErrInvalidOption)
+	}
+
+	return nil
+}
+
+// validateFloat reports negative values and values Init would replace for exceeding limit
+func validateFloat(name string, value float64, limit float64) error {
+	if value < 0 || math.IsNaN(value) {
+		return optionError(name, "%s %v must not be negative: %w", name, value, ErrInvalidOption)
+	}
+
+	if value > limit {
+		return optionError(name, "%s %v exceeds the maximum %v and would be clamped: %w", name, value, limit, ErrInvalidOption)
+	}
+
+	return nil
 }
@@ -1,6 +1,8 @@
 package universum
 
 import (
+	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -120,3 +122,57 @@ func TestOptionsInit(t *testing.T) {
 		})
 	}
 }
+
+func TestOptionsValidate(t *testing.T) {
+	valid := &Options{
+		HostAddr:     "customhost:12345",
+		ReadTimeout:  2 * time.Second,
+		ConnPoolsize: 32,
+		MinIdleConns: 8,
+		KeepAlive:    -1,
+	}
+
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := (&Options{}).Validate(); err != nil {
+		t.Fatalf("Expected zero options to be valid, got %v", err)
+	}
+
+	invalid := &Options{
+		HostAddr:        "customhost",
+		DialTimeout:     5,
+		ReadTimeout:     10 * time.Second,
+		ConnPoolsize:    70000,
+		MinIdleConns:    -1,
+		RetryBackoff:    -time.Second,
+		HedgePercentile: 1.5,
+		MaxReplyDepth:   AllowedMaxReplyDepth + 1,
+		TLSCertFile:     "client.crt",
+	}
+
+	err := invalid.Validate()
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("Expected ErrInvalidOption, got %v", err)
+	}
+
+	for _, field := range []string{"HostAddr", "DialTimeout", "ReadTimeout", "ConnPoolsize", "MinIdleConns", "RetryBackoff", "HedgePercentile", "MaxReplyDepth", "TLSKeyFile"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("Expected error to mention %s, got %v", field, err)
+		}
+	}
+
+	var optErr *OptionError
+	if !errors.As(err, &optErr) || optErr.Field != "HostAddr" {
+		t.Errorf("Expected an OptionError naming HostAddr first, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "5 * time.Second") {
+		t.Errorf("Expected the 5ns dial timeout to be reported as a unit mistake, got %v", err)
+	}
+
+	if invalid.ReadTimeout != 10*time.Second || invalid.ConnPoolsize != 70000 {
+		t.Fatalf("Expected Validate not to modify the options, got %v", invalid)
+	}
+}
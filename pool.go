@@ -19,31 +19,66 @@ var reusableTimers = sync.Pool{
 	},
 }
 
+// connPool hands out connections to callers while upholding these invariants:
+//
+//   - every checked-out connection holds exactly one turn of waitQueue, which is
+//     sized ConnPoolsize, and the turn is freed exactly once when it is returned;
+//   - poolsize counts live connections plus dials in progress, it is only changed
+//     under connMutex and never exceeds ConnPoolsize;
+//   - idle connections are always a subset of the live connections.
 type connPool struct {
 	options   *Options
 	connMutex sync.Mutex
+	dial      func(ctx context.Context, opts *Options) (connInterface, error)
+	tls       *tlsProvider
+	auth      *authenticator
+	hello     *handshaker
 
 	connections     []connInterface
 	idleConnections []connInterface
+	checkedOut      map[connInterface]struct{}
 	waitQueue       chan struct{}
 
-	poolsize     int64
-	numIdleConns int64
-	isClosed     uint32
+	poolsize       int64
+	numIdleConns   int64
+	isClosed       uint32
+	unhealthyUntil int64
+	drainedBefore  int64
+
+	reaperStop chan struct{}
+	reaperDone chan struct{}
 }
 
-func (cp *connPool) createConn() (connInterface, error) {
-	conn, err := newConnection(cp.options)
-	if err != nil {
-		return nil, err
+// reserveSlot claims room for one more live connection, it must be called
+// with connMutex held.
+func (cp *connPool) reserveSlot() bool {
+	if cp.poolsize >= cp.options.ConnPoolsize {
+		return false
 	}
 
-	if cp.poolsize < cp.options.ConnPoolsize {
-		conn.setPooled(true)
-		cp.poolsize++
+	cp.poolsize++
+	return true
+}
+
+// releaseSlot gives back a slot claimed by reserveSlot, it must be called
+// with connMutex held.
+func (cp *connPool) releaseSlot() {
+	cp.poolsize--
+}
+
+// createConn dials a new connection into a slot previously claimed with
+// reserveSlot, giving the slot back if the dial fails.
+func (cp *connPool) createConn(ctx context.Context) (connInterface, error) {
+	conn, err := cp.dial(ctx, cp.options)
+	if err != nil {
+		cp.connMutex.Lock()
+		cp.releaseSlot()
+		cp.connMutex.Unlock()
+		return nil, err
 	}
 
-	return conn, err
+	conn.setPooled(true)
+	return conn, nil
 }
 
 func (cp *connPool) GetConn(ctx context.Context) (connInterface, error) {
@@ -51,13 +86,23 @@ func (cp *connPool) GetConn(ctx context.Context) (connInterface, error) {
 		return nil, ErrConnectionPoolClosed
 	}
 
+	if until := atomic.LoadInt64(&cp.unhealthyUntil); until > 0 && time.Now().UnixNano() < until {
+		return nil, fmt.Errorf("server announced shutdown, redialing after %s: %w",
+			time.Until(time.Unix(0, until)).Round(time.Millisecond), ErrServerShuttingDown)
+	}
+
 	if err := cp.waitForTurn(ctx); err != nil {
 		return nil, err
 	}
 
 	for {
+		var reserved bool
+
 		cp.connMutex.Lock()
 		conn, err := cp.acquireIdleConnection()
+		if err == nil && conn == nil {
+			reserved = cp.reserveSlot()
+		}
 		cp.connMutex.Unlock()
 
 		if err != nil {
@@ -66,27 +111,52 @@ func (cp *connPool) GetConn(ctx context.Context) (connInterface, error) {
 		}
 
 		if conn == nil {
+			if !reserved {
+				cp.freeTurn()
+				return nil, errPoolExhausted
+			}
 			break
 		}
 
-		if !cp.isActiveConnection(conn) {
+		if cp.isIdleExpired(conn, time.Now()) || !cp.isActiveConnection(conn) {
 			cp.CloseConn(conn)
 			continue
 		}
 
+		cp.connMutex.Lock()
+		cp.checkedOut[conn] = struct{}{}
+		cp.connMutex.Unlock()
+
+		conn.setInUse(true)
+		conn.setUsedAt(time.Now())
+
+		if err := cp.auth.ensureCurrent(ctx, conn); err != nil {
+			cp.Remove(ctx, conn)
+			return nil, err
+		}
+
 		return conn, nil
 	}
 
-	newConn, err := cp.createConn()
+	newConn, err := cp.createConn(ctx)
 	if err != nil {
 		cp.freeTurn()
 		return nil, err
 	}
 
 	cp.connMutex.Lock()
+	if cp.closed() {
+		cp.connMutex.Unlock()
+		cp.discardUntracked(newConn)
+		cp.freeTurn()
+		return nil, ErrConnectionPoolClosed
+	}
+
 	cp.connections = append(cp.connections, newConn)
+	cp.checkedOut[newConn] = struct{}{}
 	cp.connMutex.Unlock()
 
+	newConn.setInUse(true)
 	return newConn, nil
 }
 
@@ -127,10 +197,6 @@ func (cp *connPool) waitForTurn(ctx context.Context) error {
 	}
 }
 
-func (cp *connPool) freeTurn() {
-	<-cp.waitQueue
-}
-
 func (cp *connPool) isActiveConnection(conn connInterface) bool {
 	now := time.Now()
 
@@ -171,11 +237,34 @@ func (cp *connPool) isActiveConnection(conn connInterface) bool {
 		return false
 	}
 
-	if sysErr != nil {
+	return sysErr == nil
+}
+
+// isIdleExpired reports whether the connection has been sitting unused in
+// the pool for longer than the configured ConnMaxIdleTime.
+func (cp *connPool) isIdleExpired(conn connInterface, now time.Time) bool {
+	return cp.options.ConnMaxIdleTime > 0 && now.Sub(conn.getUsedAt()) >= cp.options.ConnMaxIdleTime
+}
+
+// freeTurn gives back the turn taken by waitForTurn. It never blocks, so an
+// accounting error cannot hang the caller.
+func (cp *connPool) freeTurn() {
+	select {
+	case <-cp.waitQueue:
+	default:
+	}
+}
+
+// checkIn marks a checked-out connection as returned, it must be called with
+// connMutex held. It reports false if the connection was not checked out, so
+// that its turn is never freed twice.
+func (cp *connPool) checkIn(conn connInterface) bool {
+	if _, ok := cp.checkedOut[conn]; !ok {
 		return false
 	}
 
-	conn.setUsedAt(now)
+	delete(cp.checkedOut, conn)
+	conn.setInUse(false)
 	return true
 }
 
@@ -191,33 +280,33 @@ func (cp *connPool) acquireIdleConnection() (connInterface, error) {
 
 	conn := cp.idleConnections[0]
 	copy(cp.idleConnections, cp.idleConnections[1:])
+	cp.idleConnections[idleQueueSize-1] = nil
 	cp.idleConnections = cp.idleConnections[:idleQueueSize-1]
 
 	cp.numIdleConns--
 	return conn, nil
 }
 
+// ReleaseConn returns a checked-out connection to the idle queue, or closes it
+// if it cannot be reused. Releasing a connection which is not checked out is a no-op.
 func (cp *connPool) ReleaseConn(ctx context.Context, conn connInterface) {
-	if conn.getReader().Buffered() > 0 {
-		cp.Remove(ctx, conn)
-		return
-	}
-
-	if !conn.getPooled() {
-		cp.Remove(ctx, conn)
-		return
-	}
-
 	var shouldCloseConn bool
 
+	conn.setUsedAt(time.Now())
 	cp.connMutex.Lock()
 
-	if cp.numIdleConns < cp.options.ConnPoolsize {
-		cp.idleConnections = append(cp.idleConnections, conn)
-		cp.numIdleConns++
-	} else {
+	if !cp.checkIn(conn) {
+		cp.connMutex.Unlock()
+		return
+	}
+
+	if cp.closed() || conn.getReader().Buffered() > 0 || !conn.getPooled() ||
+		cp.numIdleConns >= cp.options.ConnPoolsize || cp.isDrained(conn) {
 		cp.removeConnFromPool(conn)
 		shouldCloseConn = true
+	} else {
+		cp.idleConnections = append(cp.idleConnections, conn)
+		cp.numIdleConns++
 	}
 
 	cp.connMutex.Unlock()
@@ -229,12 +318,25 @@ func (cp *connPool) ReleaseConn(ctx context.Context, conn connInterface) {
 	}
 }
 
+// Remove closes a checked-out connection and frees its turn. Removing a
+// connection which is not checked out is a no-op.
 func (cp *connPool) Remove(_ context.Context, conn connInterface) {
-	cp.removeConnFromPoolWithLock(conn)
+	cp.connMutex.Lock()
+
+	if !cp.checkIn(conn) {
+		cp.connMutex.Unlock()
+		return
+	}
+
+	cp.removeConnFromPool(conn)
+	cp.connMutex.Unlock()
+
 	cp.freeTurn()
 	cp.closeConn(conn)
 }
 
+// CloseConn closes a connection which is not checked out, such as a stale
+// idle connection, without touching any turn.
 func (cp *connPool) CloseConn(conn connInterface) error {
 	cp.removeConnFromPoolWithLock(conn)
 	return cp.closeConn(conn)
@@ -250,9 +352,7 @@ func (cp *connPool) removeConnFromPool(conn connInterface) {
 	for index, currConn := range cp.connections {
 		if currConn == conn {
 			cp.connections = append(cp.connections[:index], cp.connections[index+1:]...)
-			if conn.getPooled() {
-				cp.poolsize--
-			}
+			cp.releaseSlot()
 			break
 		}
 	}
@@ -282,23 +382,39 @@ func (cp *connPool) closed() bool {
 	return atomic.LoadUint32(&cp.isClosed) == 1
 }
 
+// Close closes every idle connection. Connections still checked out are left
+// open and closed when their callers release them, which also frees their turns.
 func (cp *connPool) Close() error {
 	if !atomic.CompareAndSwapUint32(&cp.isClosed, 0, 1) {
 		return ErrConnectionPoolClosed
 	}
 
+	if cp.reaperStop != nil {
+		close(cp.reaperStop)
+		<-cp.reaperDone
+	}
+
+	cp.tls.stop()
+	cp.auth.stop()
+
 	var firstErr error
 	cp.connMutex.Lock()
 	for _, conn := range cp.connections {
 		if conn != nil {
+			if _, ok := cp.checkedOut[conn]; ok {
+				continue
+			}
 			if err := cp.closeConn(conn); err != nil && firstErr == nil {
 				firstErr = err
 			}
 		}
 	}
 
-	cp.connections = nil
-	cp.poolsize = 0
+	cp.poolsize -= int64(len(cp.connections) - len(cp.checkedOut))
+	cp.connections = cp.connections[:0]
+	for conn := range cp.checkedOut {
+		cp.connections = append(cp.connections, conn)
+	}
 	cp.idleConnections = nil
 	cp.numIdleConns = 0
 	cp.connMutex.Unlock()
@@ -306,9 +422,255 @@ func (cp *connPool) Close() error {
 	return firstErr
 }
 
+// isDrained reports whether the connection was created before the pool was
+// last drained, e.g. because the server announced its shutdown or the TLS
+// certificates were rotated.
+func (cp *connPool) isDrained(conn connInterface) bool {
+	drainedBefore := atomic.LoadInt64(&cp.drainedBefore)
+	return drainedBefore > 0 && conn.getCreatedAt().UnixNano() < drainedBefore
+}
+
+// markUnhealthy drains the connections, which all lead to a server that
+// announced its shutdown: idle ones are closed at once and checked-out ones
+// when released. New checkouts fail fast for the given delay, after which
+// connections are dialed afresh.
+func (cp *connPool) markUnhealthy(delay time.Duration) {
+	now := time.Now()
+	atomic.StoreInt64(&cp.unhealthyUntil, now.Add(delay).UnixNano())
+	cp.drain(now)
+}
+
+// drain retires every connection created before the given time: idle ones are
+// closed right away and checked-out ones are closed once they are released.
+func (cp *connPool) drain(before time.Time) {
+	atomic.StoreInt64(&cp.drainedBefore, before.UnixNano())
+
+	cp.connMutex.Lock()
+	idle := cp.idleConnections
+	for _, conn := range idle {
+		cp.removeConnFromPool(conn)
+	}
+	cp.idleConnections = make([]connInterface, 0, cp.options.ConnPoolsize)
+	cp.numIdleConns = 0
+	cp.connMutex.Unlock()
+
+	for _, conn := range idle {
+		cp.closeConn(conn)
+	}
+}
+
+// startReaper launches the background goroutine which periodically evicts
+// expired or broken idle connections and replenishes the pool up to MinIdleConns.
+func (cp *connPool) startReaper() {
+	cp.reaperStop = make(chan struct{})
+	cp.reaperDone = make(chan struct{})
+
+	go func() {
+		defer close(cp.reaperDone)
+
+		ticker := time.NewTicker(cp.options.ConnReapInterval)
+		defer ticker.Stop()
+
+		cp.replenishIdleConns()
+
+		for {
+			select {
+			case <-cp.reaperStop:
+				return
+			case <-ticker.C:
+				cp.reapStaleConns()
+				cp.replenishIdleConns()
+			}
+		}
+	}()
+}
+
+// reapStaleConns closes idle connections that exceeded their lifetime or idle
+// time, or which fail the liveness probe. The probes run without connMutex, so
+// a slow probe never stalls the callers of the pool.
+func (cp *connPool) reapStaleConns() {
+	cp.connMutex.Lock()
+	if cp.closed() {
+		cp.connMutex.Unlock()
+		return
+	}
+	idle := append([]connInterface(nil), cp.idleConnections...)
+	cp.connMutex.Unlock()
+
+	now := time.Now()
+	for _, conn := range idle {
+		cp.reapIfStale(conn, now)
+	}
+}
+
+// reapIfStale probes an idle connection and closes it if it is stale. The
+// connection leaves the idle queue while it is probed, so that it is never
+// handed out mid-probe, and the probe holds a turn so it is accounted like
+// any other caller. Connections checked out since the snapshot are skipped.
+func (cp *connPool) reapIfStale(conn connInterface, now time.Time) {
+	select {
+	case cp.waitQueue <- struct{}{}:
+	default:
+		return
+	}
+	defer cp.freeTurn()
+
+	cp.connMutex.Lock()
+	if cp.closed() || !cp.takeIdleConn(conn) {
+		cp.connMutex.Unlock()
+		return
+	}
+	cp.connMutex.Unlock()
+
+	stale := cp.isIdleExpired(conn, now) || !cp.isActiveConnection(conn)
+
+	cp.connMutex.Lock()
+	if stale || cp.closed() || cp.isDrained(conn) {
+		cp.removeConnFromPool(conn)
+		cp.connMutex.Unlock()
+		cp.closeConn(conn)
+		return
+	}
+
+	cp.idleConnections = append(cp.idleConnections, conn)
+	cp.numIdleConns++
+	cp.connMutex.Unlock()
+}
+
+// takeIdleConn removes the connection from the idle queue, it must be called
+// with connMutex held. It reports false if the connection is no longer idle.
+func (cp *connPool) takeIdleConn(conn connInterface) bool {
+	for index, idleConn := range cp.idleConnections {
+		if idleConn == conn {
+			last := len(cp.idleConnections) - 1
+			copy(cp.idleConnections[index:], cp.idleConnections[index+1:])
+			cp.idleConnections[last] = nil
+			cp.idleConnections = cp.idleConnections[:last]
+			cp.numIdleConns--
+			return true
+		}
+	}
+
+	return false
+}
+
+// replenishIdleConns dials new connections until the pool holds at least
+// MinIdleConns idle connections. Each dial holds a turn so it is accounted
+// like any other caller, and replenishing stops when no turn is free.
+func (cp *connPool) replenishIdleConns() {
+	for {
+		select {
+		case cp.waitQueue <- struct{}{}:
+		default:
+			return
+		}
+
+		cp.connMutex.Lock()
+		needed := !cp.closed() && cp.numIdleConns < cp.options.MinIdleConns && cp.reserveSlot()
+		cp.connMutex.Unlock()
+
+		if !needed {
+			cp.freeTurn()
+			return
+		}
+
+		conn, err := cp.createConn(context.Background())
+		if err == nil {
+			err = cp.addIdleConn(conn)
+		}
+
+		cp.freeTurn()
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// warmUp dials count connections in parallel and places them in the idle
+// queue, running verify on each one before it is pooled. Connections failing
+// to dial or verify are discarded and their errors are aggregated.
+func (cp *connPool) warmUp(count int64, verify func(conn connInterface) error) error {
+	var wg sync.WaitGroup
+	errs := make([]error, count)
+
+	for i := int64(0); i < count; i++ {
+		wg.Add(1)
+
+		go func(index int64) {
+			defer wg.Done()
+			if err := cp.warmUpConn(verify); err != nil {
+				errs[index] = fmt.Errorf("connection %d of %d: %w", index+1, count, err)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func (cp *connPool) warmUpConn(verify func(conn connInterface) error) error {
+	if err := cp.waitForTurn(context.Background()); err != nil {
+		return err
+	}
+	defer cp.freeTurn()
+
+	cp.connMutex.Lock()
+	reserved := cp.reserveSlot()
+	cp.connMutex.Unlock()
+
+	if !reserved {
+		return errPoolExhausted
+	}
+
+	conn, err := cp.createConn(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if verify != nil {
+		if err := verify(conn); err != nil {
+			cp.discardUntracked(conn)
+			return err
+		}
+	}
+
+	return cp.addIdleConn(conn)
+}
+
+// addIdleConn places a freshly created connection into the idle queue.
+func (cp *connPool) addIdleConn(conn connInterface) error {
+	cp.connMutex.Lock()
+	if cp.closed() {
+		cp.connMutex.Unlock()
+		cp.discardUntracked(conn)
+		return ErrConnectionPoolClosed
+	}
+
+	conn.setUsedAt(time.Now())
+	cp.connections = append(cp.connections, conn)
+	cp.idleConnections = append(cp.idleConnections, conn)
+	cp.numIdleConns++
+	cp.connMutex.Unlock()
+
+	return nil
+}
+
+// discardUntracked closes a freshly created connection which never made it
+// into the connections list, giving its slot back to the pool.
+func (cp *connPool) discardUntracked(conn connInterface) {
+	cp.connMutex.Lock()
+	cp.releaseSlot()
+	cp.connMutex.Unlock()
+
+	cp.closeConn(conn)
+}
+
 //////////////////////////////////////////////////////////////////////////////
 
-func newConnPool(opts *Options) (*connPool, error) {
+// newConnPool creates the pool, introducing every new connection to the server
+// with the given handshaker unless it is nil.
+func newConnPool(opts *Options, hello *handshaker) (*connPool, error) {
 	if opts.ConnPoolsize <= 0 {
 		return nil, errors.New("connection pool size must be greater than 0")
 	}
@@ -318,11 +680,44 @@ func newConnPool(opts *Options) (*connPool, error) {
 		connMutex:       sync.Mutex{},
 		connections:     make([]connInterface, 0, opts.ConnPoolsize),
 		idleConnections: make([]connInterface, 0, opts.ConnPoolsize),
-		waitQueue:       make(chan struct{}, opts.ConnPoolsize*2),
+		checkedOut:      make(map[connInterface]struct{}),
+		waitQueue:       make(chan struct{}, opts.ConnPoolsize),
 		poolsize:        0,
 		numIdleConns:    0,
 		isClosed:        0,
 	}
 
+	tlsProvider, err := newTLSProvider(opts, func() { pool.drain(time.Now()) })
+	if err != nil {
+		return nil, err
+	}
+
+	pool.tls = tlsProvider
+	pool.auth = newAuthenticator(opts)
+	pool.hello = hello
+	pool.dial = func(ctx context.Context, opts *Options) (connInterface, error) {
+		conn, err := newConnection(ctx, opts, pool.tls.current())
+		if err != nil {
+			return nil, err
+		}
+
+		// authenticate and introduce the client before the connection enters the pool
+		if err := pool.auth.authenticateNew(ctx, conn); err != nil {
+			conn.close()
+			return nil, err
+		}
+
+		if err := pool.hello.handshake(ctx, conn); err != nil {
+			conn.close()
+			return nil, err
+		}
+
+		return conn, nil
+	}
+
+	if opts.ConnReapInterval > 0 && (opts.ConnMaxIdleTime > 0 || opts.MinIdleConns > 0) {
+		pool.startReaper()
+	}
+
 	return pool, nil
 }
@@ -1,8 +1,17 @@
 package universum
 
 import (
+	"bufio"
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -21,11 +30,22 @@ func mockOptions() *Options {
 	}
 }
 
+// mockPoolOptions creates mock options pointing at a local fake server
+func mockPoolOptions(t testing.TB) *Options {
+	server := newFakeServer(t, func(cmd []interface{}) interface{} {
+		return fakeReply("PONG", RespPingSuccess, "OK")
+	})
+
+	opts := mockOptions()
+	opts.HostAddr = server.addr()
+	return opts
+}
+
 // TestNewConnPool creates a new connection pool and verifies its initialization
 func TestNewConnPool(t *testing.T) {
 	opts := mockOptions()
 
-	pool, err := newConnPool(opts)
+	pool, err := newConnPool(opts, nil)
 	if err != nil {
 		t.Fatalf("Expected to create conn pool, got error: %v", err)
 	}
@@ -41,8 +61,8 @@ func TestNewConnPool(t *testing.T) {
 
 // TestGetConn acquires a connection from the pool
 func TestGetConn(t *testing.T) {
-	opts := mockOptions()
-	connPool, _ := newConnPool(opts)
+	opts := mockPoolOptions(t)
+	connPool, _ := newConnPool(opts, nil)
 
 	ctx := context.Background()
 	conn, err := connPool.GetConn(ctx)
@@ -57,8 +77,8 @@ func TestGetConn(t *testing.T) {
 
 // TestReleaseConn verifies releasing a connection back to the pool
 func TestReleaseConn(t *testing.T) {
-	opts := mockOptions()
-	pool, _ := newConnPool(opts)
+	opts := mockPoolOptions(t)
+	pool, _ := newConnPool(opts, nil)
 
 	ctx := context.Background()
 	conn, err := pool.GetConn(ctx)
@@ -77,7 +97,7 @@ func TestReleaseConn(t *testing.T) {
 // TestCloseConnPool tests the closing of the pool
 func TestCloseConnPool(t *testing.T) {
 	opts := mockOptions()
-	pool, _ := newConnPool(opts)
+	pool, _ := newConnPool(opts, nil)
 
 	err := pool.Close()
 	if err != nil {
@@ -93,7 +113,7 @@ func TestCloseConnPool(t *testing.T) {
 func TestWaitForTurnTimeout(t *testing.T) {
 	opts := mockOptions()
 	opts.ConnWaitTimeout = 500 * time.Millisecond
-	pool, _ := newConnPool(opts)
+	pool, _ := newConnPool(opts, nil)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
 	defer cancel()
@@ -108,8 +128,8 @@ func TestWaitForTurnTimeout(t *testing.T) {
 
 // TestIsActiveConnection verifies if the connection is active
 func TestIsActiveConnection(t *testing.T) {
-	opts := mockOptions()
-	pool, _ := newConnPool(opts)
+	opts := mockPoolOptions(t)
+	pool, _ := newConnPool(opts, nil)
 
 	conn, _ := pool.GetConn(context.Background())
 
@@ -118,3 +138,414 @@ func TestIsActiveConnection(t *testing.T) {
 		t.Fatal("Expected connection to be inactive, but it is active")
 	}
 }
+
+// TestReaperDisabledByDefault verifies that no reaper is started unless an
+// idle timeout or MinIdleConns is configured
+func TestReaperDisabledByDefault(t *testing.T) {
+	opts := mockOptions()
+	opts.Init()
+	pool, _ := newConnPool(opts, nil)
+	defer pool.Close()
+
+	if opts.ConnMaxIdleTime != 0 || opts.ConnReapInterval != 0 || pool.reaperStop != nil {
+		t.Fatalf("Expected the reaper to be disabled, got idle time %v and interval %v", opts.ConnMaxIdleTime, opts.ConnReapInterval)
+	}
+
+	opts = mockOptions()
+	opts.MinIdleConns = 1
+	opts.Init()
+	if opts.ConnReapInterval != DefaultConnReapInterval {
+		t.Fatalf("Expected the default reap interval with MinIdleConns, got %v", opts.ConnReapInterval)
+	}
+}
+
+// TestReaperEvictsIdleConnections verifies that idle connections past
+// ConnMaxIdleTime are closed by the background reaper
+func TestReaperEvictsIdleConnections(t *testing.T) {
+	opts := mockPoolOptions(t)
+	opts.ConnMaxIdleTime = 50 * time.Millisecond
+	opts.ConnReapInterval = 20 * time.Millisecond
+	pool, _ := newConnPool(opts, nil)
+	defer pool.Close()
+
+	ctx := context.Background()
+	conn, err := pool.GetConn(ctx)
+	if err != nil {
+		t.Fatalf("Failed to acquire connection: %v", err)
+	}
+	pool.ReleaseConn(ctx, conn)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for pool.Len() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if pool.Len() != 0 || pool.IdleLen() != 0 {
+		t.Fatalf("Expected idle connection to be reaped, got %d live and %d idle", pool.Len(), pool.IdleLen())
+	}
+}
+
+// TestReaperReplenishesMinIdleConns verifies that the pool keeps at least
+// MinIdleConns connections ready and stops the reaper on close
+func TestReaperReplenishesMinIdleConns(t *testing.T) {
+	opts := mockPoolOptions(t)
+	opts.MinIdleConns = 3
+	opts.ConnMaxIdleTime = time.Hour
+	opts.ConnReapInterval = 20 * time.Millisecond
+	pool, _ := newConnPool(opts, nil)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for pool.IdleLen() < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if pool.IdleLen() != 3 {
+		t.Fatalf("Expected 3 idle connections, got %d", pool.IdleLen())
+	}
+
+	conn, err := pool.GetConn(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to acquire connection: %v", err)
+	}
+	pool.Remove(context.Background(), conn)
+
+	deadline = time.Now().Add(2 * time.Second)
+	for pool.IdleLen() < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if pool.IdleLen() != 3 {
+		t.Fatalf("Expected pool to be replenished to 3 idle connections, got %d", pool.IdleLen())
+	}
+
+	if err := pool.Close(); err != nil {
+		t.Fatalf("Expected to close pool without error, got %v", err)
+	}
+
+	select {
+	case <-pool.reaperDone:
+	default:
+		t.Fatal("Expected reaper goroutine to be stopped after close")
+	}
+}
+
+// slowProbeConn is a socket whose liveness probe takes the given delay
+type slowProbeConn struct {
+	MockNetConn
+	delay time.Duration
+}
+
+func (c *slowProbeConn) SyscallConn() (syscall.RawConn, error) { return slowRawConn(c.delay), nil }
+
+type slowRawConn time.Duration
+
+func (c slowRawConn) Control(f func(fd uintptr)) error    { return nil }
+func (c slowRawConn) Read(f func(fd uintptr) bool) error  { time.Sleep(time.Duration(c)); return nil }
+func (c slowRawConn) Write(f func(fd uintptr) bool) error { return nil }
+
+// TestReaperProbesWithoutLock verifies that slow liveness probes of idle
+// connections do not stall the callers of the pool
+func TestReaperProbesWithoutLock(t *testing.T) {
+	opts := mockOptions()
+	opts.ConnPoolsize = 3
+	opts.ConnMaxLifetime = 0
+
+	tracker := &fakeConnTracker{limit: 3}
+	pool, _ := newConnPool(opts, nil)
+	defer pool.Close()
+
+	dial := tracker.dial(0, rand.Float64)
+	pool.dial = func(ctx context.Context, opts *Options) (connInterface, error) {
+		conn, err := dial(ctx, opts)
+		if err == nil {
+			conn.(*fakeConn).netConn = &slowProbeConn{delay: 200 * time.Millisecond}
+		}
+		return conn, err
+	}
+
+	ctx := context.Background()
+	conns := make([]connInterface, 3)
+	for i := range conns {
+		conn, err := pool.GetConn(ctx)
+		if err != nil {
+			t.Fatalf("Failed to acquire connection: %v", err)
+		}
+		conns[i] = conn
+	}
+	for _, conn := range conns {
+		pool.ReleaseConn(ctx, conn)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		pool.reapStaleConns()
+		close(done)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+
+	start := time.Now()
+	pool.IdleLen()
+	pool.Len()
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("Expected the pool to stay responsive while probing, blocked for %s", elapsed)
+	}
+
+	<-done
+
+	if pool.IdleLen() != 3 {
+		t.Fatalf("Expected the healthy connections to be idle again, got %d", pool.IdleLen())
+	}
+	if violations := checkPoolInvariants(pool); len(violations) > 0 {
+		t.Fatalf("Expected no invariant violations, got %v", violations)
+	}
+}
+
+// fakeConnTracker counts the sockets opened by fakeConn and records every
+// violation of the pool invariants it observes
+type fakeConnTracker struct {
+	mu         sync.Mutex
+	limit      int64
+	open       int64
+	violations []string
+}
+
+func (ft *fakeConnTracker) violate(format string, args ...interface{}) {
+	ft.mu.Lock()
+	if len(ft.violations) < 10 {
+		ft.violations = append(ft.violations, fmt.Sprintf(format, args...))
+	}
+	ft.mu.Unlock()
+}
+
+func (ft *fakeConnTracker) dial(failRate float64, rng func() float64) func(ctx context.Context, opts *Options) (connInterface, error) {
+	return func(ctx context.Context, opts *Options) (connInterface, error) {
+		if rng() < failRate {
+			return nil, ErrConnectionDialFailed
+		}
+
+		ft.mu.Lock()
+		ft.open++
+		open := ft.open
+		ft.mu.Unlock()
+
+		if open > ft.limit {
+			ft.violate("%d live sockets exceed pool size %d", open, ft.limit)
+		}
+
+		return &fakeConn{tracker: ft, reader: bufio.NewReader(strings.NewReader(""))}, nil
+	}
+}
+
+// fakeConn is an in-memory connInterface which reports double closes and
+// use of closed connections to its tracker
+type fakeConn struct {
+	tracker   *fakeConnTracker
+	reader    *bufio.Reader
+	createdAt time.Time
+	usedAt    int64
+	inUse     int32
+	pooled    int32
+	closed    int32
+	authGen   int64
+	netConn   net.Conn
+}
+
+func (fc *fakeConn) write(content []byte) (int, error) { return len(content), nil }
+func (fc *fakeConn) getCreatedAt() time.Time           { return fc.createdAt }
+func (fc *fakeConn) getUsedAt() time.Time              { return time.Unix(0, atomic.LoadInt64(&fc.usedAt)) }
+func (fc *fakeConn) getInUse() bool                    { return atomic.LoadInt32(&fc.inUse) == 1 }
+func (fc *fakeConn) getRemoteAddr() net.Addr           { return nil }
+func (fc *fakeConn) getNetConn() net.Conn {
+	if fc.netConn != nil {
+		return fc.netConn
+	}
+	return &MockNetConn{}
+}
+func (fc *fakeConn) getPooled() bool             { return atomic.LoadInt32(&fc.pooled) == 1 }
+func (fc *fakeConn) getReader() *bufio.Reader    { return fc.reader }
+func (fc *fakeConn) getWriter() *bufio.Writer    { return bufio.NewWriter(io.Discard) }
+func (fc *fakeConn) setUsedAt(t time.Time)       { atomic.StoreInt64(&fc.usedAt, t.UnixNano()) }
+func (fc *fakeConn) setCreatedAt(t time.Time)    { fc.createdAt = t }
+func (fc *fakeConn) getAuthGeneration() int64    { return atomic.LoadInt64(&fc.authGen) }
+func (fc *fakeConn) setAuthGeneration(gen int64) { atomic.StoreInt64(&fc.authGen, gen) }
+
+func (fc *fakeConn) deadline(ctx context.Context, timeout time.Duration) time.Time {
+	return noDeadline
+}
+
+func (fc *fakeConn) setInUse(state bool) {
+	if state {
+		if atomic.LoadInt32(&fc.closed) == 1 {
+			fc.tracker.violate("closed connection handed out")
+		}
+		atomic.StoreInt32(&fc.inUse, 1)
+	} else {
+		atomic.StoreInt32(&fc.inUse, 0)
+	}
+}
+
+func (fc *fakeConn) setPooled(pooled bool) {
+	if pooled {
+		atomic.StoreInt32(&fc.pooled, 1)
+	} else {
+		atomic.StoreInt32(&fc.pooled, 0)
+	}
+}
+
+func (fc *fakeConn) close() error {
+	if !atomic.CompareAndSwapInt32(&fc.closed, 0, 1) {
+		fc.tracker.violate("connection closed twice")
+		return nil
+	}
+
+	fc.tracker.mu.Lock()
+	fc.tracker.open--
+	fc.tracker.mu.Unlock()
+	return nil
+}
+
+// checkPoolInvariants verifies the accounting invariants documented on connPool
+func checkPoolInvariants(pool *connPool) []string {
+	pool.connMutex.Lock()
+	defer pool.connMutex.Unlock()
+
+	var violations []string
+	live := make(map[connInterface]bool, len(pool.connections))
+	for _, conn := range pool.connections {
+		live[conn] = true
+	}
+
+	if pool.poolsize > pool.options.ConnPoolsize {
+		violations = append(violations, fmt.Sprintf("poolsize %d exceeds %d", pool.poolsize, pool.options.ConnPoolsize))
+	}
+	if int64(len(pool.connections)) > pool.poolsize {
+		violations = append(violations, fmt.Sprintf("%d tracked connections exceed poolsize %d", len(pool.connections), pool.poolsize))
+	}
+	if int64(len(pool.idleConnections)) != pool.numIdleConns {
+		violations = append(violations, fmt.Sprintf("idle count %d does not match idle queue %d", pool.numIdleConns, len(pool.idleConnections)))
+	}
+	for _, conn := range pool.idleConnections {
+		if !live[conn] {
+			violations = append(violations, "idle connection is not live")
+		}
+		if _, ok := pool.checkedOut[conn]; ok {
+			violations = append(violations, "connection is both idle and checked out")
+		}
+	}
+	for conn := range pool.checkedOut {
+		if !live[conn] {
+			violations = append(violations, "checked-out connection is not live")
+		}
+	}
+	if len(pool.checkedOut) > len(pool.waitQueue) {
+		violations = append(violations, fmt.Sprintf("%d checked-out connections hold only %d turns", len(pool.checkedOut), len(pool.waitQueue)))
+	}
+
+	return violations
+}
+
+// TestPoolAccountingModel drives the pool with random concurrent operations
+// against fake connections and verifies the accounting invariants throughout
+func TestPoolAccountingModel(t *testing.T) {
+	seed := time.Now().UnixNano()
+	t.Logf("random seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	for round := 0; round < 20; round++ {
+		poolsize := int64(1 + rng.Intn(6))
+		workers := 1 + rng.Intn(12)
+		closeMidway := rng.Intn(4) == 0
+		roundSeed := rng.Int63()
+
+		t.Run(fmt.Sprintf("round-%d", round), func(t *testing.T) {
+			runPoolModel(t, poolsize, workers, closeMidway, roundSeed)
+		})
+	}
+}
+
+func runPoolModel(t *testing.T, poolsize int64, workers int, closeMidway bool, seed int64) {
+	var rngMu sync.Mutex
+	rng := rand.New(rand.NewSource(seed))
+	random := func() float64 {
+		rngMu.Lock()
+		defer rngMu.Unlock()
+		return rng.Float64()
+	}
+
+	opts := mockOptions()
+	opts.ConnPoolsize = poolsize
+	opts.ConnWaitTimeout = 50 * time.Millisecond
+	opts.ConnMaxLifetime = 0
+
+	tracker := &fakeConnTracker{limit: poolsize}
+	pool, _ := newConnPool(opts, nil)
+	pool.dial = tracker.dial(0.1, random)
+
+	var wg sync.WaitGroup
+	var closeOnce sync.Once
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for op := 0; op < 200; op++ {
+				if closeMidway && op == 100 && random() < 0.2 {
+					closeOnce.Do(func() { pool.Close() })
+				}
+
+				ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+				conn, err := pool.GetConn(ctx)
+				cancel()
+
+				if err != nil {
+					continue
+				}
+
+				if random() < 0.3 {
+					time.Sleep(time.Duration(random()*200) * time.Microsecond)
+				}
+
+				switch choice := random(); {
+				case choice < 0.6:
+					pool.ReleaseConn(context.Background(), conn)
+				case choice < 0.9:
+					pool.Remove(context.Background(), conn)
+				default:
+					pool.ReleaseConn(context.Background(), conn)
+					pool.Remove(context.Background(), conn)
+				}
+
+				if violations := checkPoolInvariants(pool); len(violations) > 0 {
+					tracker.violate("%v", violations)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if len(tracker.violations) > 0 {
+		t.Fatalf("pool invariants violated: %v", tracker.violations)
+	}
+
+	if len(pool.waitQueue) != 0 {
+		t.Errorf("Expected every turn to be freed, %d still held", len(pool.waitQueue))
+	}
+	if len(pool.checkedOut) != 0 {
+		t.Errorf("Expected no checked-out connections, got %d", len(pool.checkedOut))
+	}
+	if pool.poolsize != int64(len(pool.connections)) {
+		t.Errorf("Expected poolsize %d to match live connections %d", pool.poolsize, len(pool.connections))
+	}
+	if tracker.open != int64(len(pool.connections)) {
+		t.Errorf("Expected %d open sockets, got %d", len(pool.connections), tracker.open)
+	}
+
+	pool.Close()
+	if tracker.open != 0 || pool.poolsize != 0 {
+		t.Errorf("Expected all sockets closed after Close, got %d open and poolsize %d", tracker.open, pool.poolsize)
+	}
+}
@@ -0,0 +1,202 @@
+package universum
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// recordingHeader starts every recording, describing the line format
+const recordingHeader = "# universum traffic recording\n" +
+	"# <connection> > <request> | <connection> < <response>, frames quoted as Go strings\n"
+
+const (
+	frameRequest  = ">"
+	frameResponse = "<"
+)
+
+// redactedArg replaces the arguments of recorded AUTH requests
+const redactedArg = "<redacted>"
+
+// Recorder captures the RESP3 traffic of client connections into a file, one
+// request or response frame per line, e.g.
+//
+//	1 > "*2\r\n+GET\r\n+key\r\n"
+//	1 < "*3\r\n_\r\n:5001\r\n+\r\n\x04\x04\x04\x04"
+//
+// Set it as Options.Recorder to record every connection dialed by the client;
+// the traffic is recorded after TLS decryption. Recordings are replayed with
+// a Replayer.
+//
+// Recordings contain the keys and values sent and received, so treat them like
+// the data itself. Only the arguments of AUTH are redacted, as credentials.
+type Recorder struct {
+	mu     sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+	nextID int64
+	err    error
+}
+
+// NewRecorder creates or truncates the recording file at path.
+func NewRecorder(path string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording %s: %w", path, err)
+	}
+
+	r := &Recorder{file: file, writer: bufio.NewWriter(file)}
+	if _, err := r.writer.WriteString(recordingHeader); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write recording %s: %w", path, err)
+	}
+
+	return r, nil
+}
+
+// Close flushes the recording and closes the file, returning the first
+// error which occurred while recording.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return r.err
+	}
+
+	if err := r.writer.Flush(); err != nil && r.err == nil {
+		r.err = err
+	}
+
+	if err := r.file.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+
+	r.file = nil
+	return r.err
+}
+
+// wrap returns a connection recording the traffic of conn
+func (r *Recorder) wrap(conn net.Conn) net.Conn {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	return &recordingConn{Conn: conn, recorder: r, id: r.nextID}
+}
+
+// writeFrame appends a frame of the connection to the recording
+func (r *Recorder) writeFrame(id int64, direction string, frame []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil || r.err != nil {
+		return
+	}
+
+	line := strconv.FormatInt(id, 10) + " " + direction + " " + strconv.Quote(string(frame)) + "\n"
+	if _, err := r.writer.WriteString(line); err != nil {
+		r.err = err
+		return
+	}
+
+	// flush every frame so that the recording survives a crashing test
+	if err := r.writer.Flush(); err != nil {
+		r.err = err
+	}
+}
+
+// recordingConn collects the bytes written and read on a connection into
+// frames. A request ends when the client starts reading, and a response when
+// it ends with the delimiter or the client writes again.
+type recordingConn struct {
+	net.Conn
+	recorder *Recorder
+	id       int64
+
+	mu       sync.Mutex
+	request  bytes.Buffer
+	response bytes.Buffer
+}
+
+func (c *recordingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.flushFrame(&c.response, frameResponse)
+	c.request.Write(p[:n])
+
+	return n, err
+}
+
+func (c *recordingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.flushFrame(&c.request, frameRequest)
+	c.response.Write(p[:n])
+
+	if bytes.HasSuffix(c.response.Bytes(), []byte(remoteByteDelimiter)) || (err == io.EOF && c.response.Len() > 0) {
+		c.flushFrame(&c.response, frameResponse)
+	}
+
+	return n, err
+}
+
+func (c *recordingConn) Close() error {
+	c.mu.Lock()
+	c.flushFrame(&c.request, frameRequest)
+	c.flushFrame(&c.response, frameResponse)
+	c.mu.Unlock()
+
+	return c.Conn.Close()
+}
+
+// flushFrame records the buffered frame, if any. The caller must hold the lock.
+func (c *recordingConn) flushFrame(frame *bytes.Buffer, direction string) {
+	if frame.Len() == 0 {
+		return
+	}
+
+	if direction == frameRequest {
+		c.recorder.writeFrame(c.id, direction, redactRequest(frame.Bytes()))
+	} else {
+		c.recorder.writeFrame(c.id, direction, frame.Bytes())
+	}
+	frame.Reset()
+}
+
+// redactRequest replaces the credentials of an AUTH request, keeping the
+// number of arguments. Other requests are returned unchanged.
+func redactRequest(frame []byte) []byte {
+	if !bytes.Contains(frame, []byte(commandAuth)) {
+		return frame
+	}
+
+	decoded, err := decodeResp(bufio.NewReaderSize(bytes.NewReader(frame), len(frame)))
+	if err != nil || commandName(decoded) != commandAuth {
+		return frame
+	}
+
+	cmd := decoded.([]interface{})
+	redacted := []interface{}{commandAuth}
+	for range cmd[1:] {
+		redacted = append(redacted, redactedArg)
+	}
+
+	encoded, err := encodeResp(redacted)
+	if err != nil {
+		return []byte(redactedArg)
+	}
+
+	return []byte(encoded)
+}
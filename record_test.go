@@ -0,0 +1,176 @@
+package universum_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cshekharsharma/universum-client-go"
+	"github.com/cshekharsharma/universum-client-go/universumtest"
+)
+
+// runRecordedCommands runs the same commands against a recorded or replayed
+// client and returns their results
+func runRecordedCommands(t *testing.T, opts *universum.Options) []interface{} {
+	t.Helper()
+
+	client, err := universum.NewClient(opts)
+	if err != nil {
+		t.Fatalf("Expected no error while creating client, got %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	var results []interface{}
+
+	record := func(result interface{}, err error) {
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		results = append(results, result)
+	}
+
+	record(client.Set(ctx, "greeting", "hello", 0))
+	record(client.Append(ctx, "greeting", " world"))
+	record(client.Get(ctx, "greeting"))
+	record(client.MSet(ctx, map[string]interface{}{"a": 1, "b": 2, "c": 3}))
+	record(client.MGet(ctx, []string{"a", "b", "c", "missing"}))
+	record(client.Increment(ctx, "a", 41))
+	record(client.Set(ctx, "large", strings.Repeat("x", 64*1024), 0))
+
+	return results
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traffic.log")
+
+	recorder, err := universum.NewRecorder(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	opts := universumtest.NewServer(t).Options()
+	opts.Recorder = recorder
+	recorded := runRecordedCommands(t, opts)
+
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Expected no error while closing the recorder, got %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !strings.Contains(string(content), `1 > "*2\r\n+GET\r\n+greeting\r\n"`) {
+		t.Fatalf("Expected a readable GET request in the recording, got:\n%s", content)
+	}
+
+	replayer, err := universum.NewReplayer(path)
+	if err != nil {
+		t.Fatalf("Expected no error while loading the recording, got %v", err)
+	}
+
+	replayed := runRecordedCommands(t, &universum.Options{HostAddr: "replay:11191", Dialer: replayer.Dial})
+
+	if !reflect.DeepEqual(recorded, replayed) {
+		t.Fatalf("Expected replayed results %#v, got %#v", recorded, replayed)
+	}
+
+	if remaining := replayer.Remaining(); remaining != 0 {
+		t.Fatalf("Expected every recorded request to be replayed, %d left", remaining)
+	}
+
+	if err := replayer.Err(); err != nil {
+		t.Fatalf("Expected no unexpected request, got %v", err)
+	}
+}
+
+func TestRecord_RedactsCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traffic.log")
+
+	recorder, err := universum.NewRecorder(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	opts := universumtest.NewServer(t, universumtest.WithCredentials("alice", "s3cret")).Options()
+	opts.Recorder = recorder
+	recorded := runRecordedCommands(t, opts)
+	recorder.Close()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if strings.Contains(string(content), "s3cret") || strings.Contains(string(content), "alice") {
+		t.Fatalf("Expected the credentials to be redacted, got:\n%s", content)
+	}
+	if !strings.Contains(string(content), `"*3\r\n+AUTH\r\n+<redacted>\r\n+<redacted>\r\n"`) {
+		t.Fatalf("Expected a redacted AUTH request in the recording, got:\n%s", content)
+	}
+
+	replayer, err := universum.NewReplayer(path)
+	if err != nil {
+		t.Fatalf("Expected no error while loading the recording, got %v", err)
+	}
+
+	replayed := runRecordedCommands(t, &universum.Options{HostAddr: "replay:11191", Dialer: replayer.Dial,
+		Username: "bob", Password: "other"})
+
+	if !reflect.DeepEqual(recorded, replayed) {
+		t.Fatalf("Expected replayed results %#v, got %#v", recorded, replayed)
+	}
+	if err := replayer.Err(); err != nil {
+		t.Fatalf("Expected AUTH to replay regardless of its arguments, got %v", err)
+	}
+}
+
+func TestReplay_UnexpectedRequest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traffic.log")
+
+	recorder, err := universum.NewRecorder(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	opts := universumtest.NewServer(t).Options()
+	opts.Recorder = recorder
+	runRecordedCommands(t, opts)
+	recorder.Close()
+
+	replayer, err := universum.NewReplayer(path)
+	if err != nil {
+		t.Fatalf("Expected no error while loading the recording, got %v", err)
+	}
+
+	client, err := universum.NewClient(&universum.Options{HostAddr: "replay:11191", Dialer: replayer.Dial})
+	if err != nil {
+		t.Fatalf("Expected no error while creating client, got %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.Get(context.Background(), "never-recorded"); !errors.Is(err, universum.ErrReplayMismatch) {
+		t.Fatalf("Expected the command to fail with ErrReplayMismatch, got %v", err)
+	}
+
+	if err := replayer.Err(); !errors.Is(err, universum.ErrReplayMismatch) {
+		t.Fatalf("Expected ErrReplayMismatch, got %v", err)
+	}
+}
+
+func TestReplay_InvalidRecording(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traffic.log")
+	if err := os.WriteFile(path, []byte("1 < \"orphan response\"\n"), 0o600); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := universum.NewReplayer(path); !errors.Is(err, universum.ErrInvalidRecording) {
+		t.Fatalf("Expected ErrInvalidRecording, got %v", err)
+	}
+}
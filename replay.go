@@ -0,0 +1,275 @@
+package universum
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRecordedFrame is the maximum size of a line of a recording
+const maxRecordedFrame = 64 * 1024 * 1024
+
+// exchange is a recorded request with the response it received. A nil
+// response means the server closed the connection instead of replying.
+type exchange struct {
+	request  []byte
+	decoded  interface{}
+	response []byte
+	replayed bool
+}
+
+// Replayer serves the responses of a recording made with a Recorder, so that
+// the client can run without a server. Set its Dial method as Options.Dialer,
+// with TLS disabled as recordings hold the decrypted traffic.
+//
+// Every request is answered with the response of the first recorded request
+// with the same command and arguments which was not replayed yet, no matter
+// which connection it was recorded on. The connection setup commands HELLO
+// and AUTH may be replayed any number of times, and match regardless of their
+// arguments, since HELLO includes the client ID and AUTH is recorded with its
+// credentials redacted. Unexpected requests fail the read with ErrReplayMismatch.
+type Replayer struct {
+	mu        sync.Mutex
+	exchanges []*exchange
+	err       error
+}
+
+// NewReplayer loads the recording at path.
+func NewReplayer(path string) (*Replayer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording %s: %w", path, err)
+	}
+	defer file.Close()
+
+	r := &Replayer{}
+	pending := make(map[string]*exchange)
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordedFrame)
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, " ", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("line %d of recording %s is not a frame: %w", lineNo, path, ErrInvalidRecording)
+		}
+
+		id, direction := parts[0], parts[1]
+		frame, err := strconv.Unquote(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d of recording %s has an invalid frame [%v]: %w", lineNo, path, err, ErrInvalidRecording)
+		}
+
+		switch direction {
+		case frameRequest:
+			decoded, err := decodeResp(bufio.NewReaderSize(strings.NewReader(frame), len(frame)))
+			if err != nil {
+				return nil, fmt.Errorf("line %d of recording %s has an invalid request [%v]: %w", lineNo, path, err, ErrInvalidRecording)
+			}
+
+			ex := &exchange{request: []byte(frame), decoded: decoded}
+			r.exchanges = append(r.exchanges, ex)
+			pending[id] = ex
+
+		case frameResponse:
+			ex, ok := pending[id]
+			if !ok {
+				return nil, fmt.Errorf("line %d of recording %s has a response without request: %w", lineNo, path, ErrInvalidRecording)
+			}
+			ex.response = append(ex.response, frame...)
+
+		default:
+			return nil, fmt.Errorf("line %d of recording %s has an unknown direction %q: %w", lineNo, path, direction, ErrInvalidRecording)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recording %s: %w", path, err)
+	}
+
+	return r, nil
+}
+
+// Dial returns a connection answering from the recording, with the signature of Options.Dialer.
+func (r *Replayer) Dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return &replayConn{replayer: r}, nil
+}
+
+// Remaining returns the number of recorded requests, apart from the connection
+// setup commands, which were not replayed.
+func (r *Replayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	remaining := 0
+	for _, ex := range r.exchanges {
+		if !ex.replayed && !isSetupCommand(ex.decoded) {
+			remaining++
+		}
+	}
+
+	return remaining
+}
+
+// Err returns the first unexpected request, wrapped in ErrReplayMismatch.
+func (r *Replayer) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.err
+}
+
+// replay finds the recorded exchange answering the request
+func (r *Replayer) replay(request []byte) (*exchange, error) {
+	decoded, decodeErr := decodeResp(bufio.NewReaderSize(bytes.NewReader(request), len(request)))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if decodeErr == nil {
+		var replayedSetup *exchange
+
+		for _, ex := range r.exchanges {
+			if !sameRequest(ex.decoded, decoded) {
+				continue
+			}
+
+			if !ex.replayed {
+				ex.replayed = true
+				return ex, nil
+			}
+
+			if replayedSetup == nil && isSetupCommand(ex.decoded) {
+				replayedSetup = ex
+			}
+		}
+
+		if replayedSetup != nil {
+			return replayedSetup, nil
+		}
+	}
+
+	err := fmt.Errorf("unexpected request %q: %w", request, ErrReplayMismatch)
+	if r.err == nil {
+		r.err = err
+	}
+
+	return nil, err
+}
+
+// commandName returns the name of a decoded command
+func commandName(decoded interface{}) string {
+	if cmd, ok := decoded.([]interface{}); ok && len(cmd) > 0 {
+		name, _ := cmd[0].(string)
+		return name
+	}
+
+	return ""
+}
+
+func isSetupCommand(decoded interface{}) bool {
+	name := commandName(decoded)
+	return name == commandHello || name == commandAuth
+}
+
+// sameRequest reports whether two decoded requests are equal, treating all
+// HELLO requests, and all AUTH requests, as equal
+func sameRequest(recorded, request interface{}) bool {
+	if name := commandName(recorded); isSetupCommand(recorded) && commandName(request) == name {
+		return true
+	}
+
+	return reflect.DeepEqual(recorded, request)
+}
+
+// replayConn is a connection which answers every request written to it from
+// the recording of its Replayer
+type replayConn struct {
+	replayer *Replayer
+
+	mu      sync.Mutex
+	request bytes.Buffer
+	pending bytes.Buffer
+	eof     bool
+	closed  bool
+}
+
+func (c *replayConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return 0, net.ErrClosed
+	}
+
+	return c.request.Write(p)
+}
+
+func (c *replayConn) Read(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return 0, net.ErrClosed
+	}
+
+	if c.pending.Len() == 0 && c.request.Len() > 0 {
+		ex, err := c.replayer.replay(c.request.Bytes())
+		c.request.Reset()
+		if err != nil {
+			return 0, err
+		}
+
+		c.pending.Write(ex.response)
+
+		// the server closed the connection instead of completing the reply
+		c.eof = !bytes.HasSuffix(ex.response, []byte(remoteByteDelimiter))
+	}
+
+	if c.pending.Len() == 0 {
+		if c.eof {
+			return 0, io.EOF
+		}
+		return 0, fmt.Errorf("read without a pending request: %w", ErrReplayMismatch)
+	}
+
+	return c.pending.Read(p)
+}
+
+func (c *replayConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	return nil
+}
+
+func (c *replayConn) LocalAddr() net.Addr                { return replayAddr{} }
+func (c *replayConn) RemoteAddr() net.Addr               { return replayAddr{} }
+func (c *replayConn) SetDeadline(t time.Time) error      { return nil }
+func (c *replayConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *replayConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// replayAddr is the address of both ends of a replayed connection
+type replayAddr struct{}
+
+func (replayAddr) Network() string { return "replay" }
+func (replayAddr) String() string  { return "replay" }
@@ -0,0 +1,220 @@
+package universum_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cshekharsharma/universum-client-go"
+	"github.com/cshekharsharma/universum-client-go/universumtest"
+)
+
+// newFaultyClient starts a test server and a client with short timeouts, so
+// that injected faults surface quickly
+func newFaultyClient(t *testing.T, configure func(opts *universum.Options)) (*universumtest.Server, *universum.Client) {
+	t.Helper()
+
+	server := universumtest.NewServer(t)
+
+	opts := server.Options()
+	opts.ReadTimeout = 200 * time.Millisecond
+	opts.WriteTimeout = 200 * time.Millisecond
+	opts.ConnPoolsize = 2
+	opts.MaxRetries = 1
+	if configure != nil {
+		configure(opts)
+	}
+
+	client, err := universum.NewClient(opts)
+	if err != nil {
+		t.Fatalf("Expected no error while creating client, got %v", err)
+	}
+
+	t.Cleanup(func() { client.Close() })
+	return server, client
+}
+
+// expectRecovery checks that the client serves commands again once the fault is gone
+func expectRecovery(t *testing.T, client *universum.Client) {
+	t.Helper()
+
+	for i := 0; i < 3; i++ {
+		result, err := client.Set(context.Background(), "recovered", "yes", 0)
+		if err != nil {
+			t.Fatalf("Expected the client to recover, got %v", err)
+		}
+
+		if result.Code != universum.RespRecordUpdated {
+			t.Fatalf("Expected code %d, got %d", universum.RespRecordUpdated, result.Code)
+		}
+	}
+}
+
+func TestResilience_ReadFaults(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fault universumtest.Fault
+	}{
+		{name: "latency above read timeout", fault: universumtest.Fault{Latency: 500 * time.Millisecond}},
+		{name: "dropped connection", fault: universumtest.Fault{Drop: true}},
+		{name: "dropped mid-reply", fault: universumtest.Fault{DropAfter: 5}},
+		{name: "garbage bytes", fault: universumtest.Fault{Garbage: []byte("\x00\xffnot resp")}},
+		{name: "missing delimiter", fault: universumtest.Fault{NoDelimiter: true}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server, client := newFaultyClient(t, nil)
+			ctx := context.Background()
+
+			if _, err := client.Ping(ctx); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			tc.fault.Times = 1
+			server.Inject("GET", tc.fault)
+
+			if _, err := client.Get(ctx, "key"); err == nil {
+				t.Fatal("Expected the fault to fail the command")
+			}
+
+			expectRecovery(t, client)
+		})
+	}
+}
+
+func TestResilience_PartialWrites(t *testing.T) {
+	server, client := newFaultyClient(t, nil)
+	ctx := context.Background()
+
+	server.Inject(universumtest.AnyCommand, universumtest.Fault{ChunkSize: 3, ChunkDelay: 5 * time.Millisecond})
+
+	if _, err := client.Set(ctx, "key", "a value spanning several partial writes", 0); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	result, err := client.Get(ctx, "key")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Value != "a value spanning several partial writes" {
+		t.Fatalf("Expected the value to be reassembled, got %v", result.Value)
+	}
+}
+
+func TestResilience_DroppedConnections(t *testing.T) {
+	server, client := newFaultyClient(t, nil)
+	ctx := context.Background()
+
+	if _, err := client.Ping(ctx); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	server.DropConnections()
+	time.Sleep(50 * time.Millisecond)
+
+	// idle connections closed by the server are detected before reuse
+	expectRecovery(t, client)
+}
+
+func TestResilience_ServerBusy(t *testing.T) {
+	busy := universumtest.Fault{Code: universum.RespServerBusy, Times: 2}
+
+	server, client := newFaultyClient(t, nil)
+	server.Inject("GET", busy)
+
+	result, err := client.Get(context.Background(), "key")
+	if err != nil || result.Code != universum.RespServerBusy {
+		t.Fatalf("Expected the busy reply to be returned without backpressure, got %+v, %v", result, err)
+	}
+
+	server, client = newFaultyClient(t, func(opts *universum.Options) {
+		opts.AdaptiveBackpressure = true
+		opts.RetryBackoff = time.Millisecond
+	})
+	server.Inject("GET", busy)
+
+	if _, err := client.Get(context.Background(), "key"); !errors.Is(err, universum.ErrServerBusy) {
+		t.Fatalf("Expected ErrServerBusy once the retries are exhausted, got %v", err)
+	}
+
+	server, client = newFaultyClient(t, func(opts *universum.Options) {
+		opts.AdaptiveBackpressure = true
+		opts.MaxRetries = 3
+		opts.RetryBackoff = time.Millisecond
+	})
+	server.Inject("GET", busy)
+
+	result, err = client.Get(context.Background(), "key")
+	if err != nil {
+		t.Fatalf("Expected busy replies to be retried, got %v", err)
+	}
+
+	if result.Code != universum.RespRecordNotFound {
+		t.Fatalf("Expected code %d, got %d", universum.RespRecordNotFound, result.Code)
+	}
+}
+
+func TestResilience_ServerShuttingDown(t *testing.T) {
+	server, client := newFaultyClient(t, func(opts *universum.Options) {
+		opts.ShutdownRedialDelay = 100 * time.Millisecond
+	})
+	ctx := context.Background()
+
+	server.Inject(universumtest.AnyCommand, universumtest.Fault{Code: universum.RespServerShuttingDown, Times: 1})
+
+	if _, err := client.Get(ctx, "key"); !errors.Is(err, universum.ErrServerShuttingDown) {
+		t.Fatalf("Expected ErrServerShuttingDown, got %v", err)
+	}
+
+	if _, err := client.Get(ctx, "key"); !errors.Is(err, universum.ErrServerShuttingDown) {
+		t.Fatalf("Expected commands to fail fast while draining, got %v", err)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	expectRecovery(t, client)
+}
+
+func TestResilience_SlowAccept(t *testing.T) {
+	server, client := newFaultyClient(t, func(opts *universum.Options) {
+		opts.ReadTimeout = time.Second
+	})
+	server.SetAcceptDelay(100 * time.Millisecond)
+
+	start := time.Now()
+	if _, err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Fatalf("Expected the accept delay to slow down the first command, took %v", elapsed)
+	}
+
+	server.ClearFaults()
+}
+
+func TestResilience_ScriptedFaults(t *testing.T) {
+	server, client := newFaultyClient(t, nil)
+	ctx := context.Background()
+
+	server.Inject("GET",
+		universumtest.Fault{Drop: true, Times: 1},
+		universumtest.Fault{Code: universum.RespServerBusy, Times: 1},
+	)
+
+	if _, err := client.Get(ctx, "key"); err == nil {
+		t.Fatal("Expected the dropped connection to fail the first GET")
+	}
+
+	result, err := client.Get(ctx, "key")
+	if err != nil || result.Code != universum.RespServerBusy {
+		t.Fatalf("Expected the second GET to be answered as busy, got %+v, %v", result, err)
+	}
+
+	result, err = client.Get(ctx, "key")
+	if err != nil || result.Code != universum.RespRecordNotFound {
+		t.Fatalf("Expected the third GET to succeed, got %+v, %v", result, err)
+	}
+}
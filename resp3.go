@@ -2,16 +2,140 @@ package universum
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
 
 	"github.com/cshekharsharma/resp-go/resp3"
 )
 
+// errIncompleteResp marks RESP data which ends before the value is complete
+var errIncompleteResp = errors.New("incomplete RESP value")
+
 // Wrapper function for resp3.Encode
 func encodeResp(value interface{}) (string, error) {
 	return resp3.Encode(value)
 }
 
-// Wrapper function for resp3.Decode
-func decodeResp(reader *bufio.Reader) (interface{}, error) {
+// Wrapper function for resp3.Decode, turning decoder panics on hostile input
+// into ErrMalformedResponseReceived
+func decodeResp(reader *bufio.Reader) (value interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			value = nil
+			err = fmt.Errorf("failed to decode RESP value [%v]: %w", r, ErrMalformedResponseReceived)
+		}
+	}()
+
 	return resp3.Decode(reader)
 }
+
+// respLevel is an aggregate value whose elements are being validated
+type respLevel struct {
+	remaining int64
+	isMap     bool
+}
+
+// validateResp checks that data holds exactly one well-formed RESP3 value
+// which the decoder can handle, nested no deeper than maxDepth aggregates if
+// maxDepth is positive. It rejects negative or oversized lengths and element
+// counts, map keys which are not scalars and trailing bytes, and returns
+// errIncompleteResp if the data ends before the value is complete.
+func validateResp(data []byte, maxDepth int64) error {
+	pos := 0
+	levels := []respLevel{{remaining: 1}}
+
+	for len(levels) > 0 {
+		level := &levels[len(levels)-1]
+		if level.remaining == 0 {
+			levels = levels[:len(levels)-1]
+			continue
+		}
+
+		isKey := level.isMap && level.remaining%2 == 0
+		level.remaining--
+
+		if pos >= len(data) {
+			return errIncompleteResp
+		}
+
+		kind := data[pos]
+		end := bytes.Index(data[pos+1:], []byte("\r\n"))
+		if end < 0 {
+			return errIncompleteResp
+		}
+
+		line := string(data[pos+1 : pos+1+end])
+		pos += end + 3
+
+		switch kind {
+		case '+', '-', ':', ',':
+			// the decoder reports invalid numbers as errors
+
+		case '_':
+			if line != "" {
+				return fmt.Errorf("null with content %q: %w", line, ErrMalformedResponseReceived)
+			}
+
+		case '#':
+			if line != "t" && line != "f" {
+				return fmt.Errorf("invalid boolean %q: %w", line, ErrMalformedResponseReceived)
+			}
+
+		case '$', '=', '!':
+			length, err := strconv.ParseInt(line, 10, 64)
+			if err != nil || length < -1 || (length == -1 && kind == '!') {
+				return fmt.Errorf("invalid length %q: %w", line, ErrMalformedResponseReceived)
+			}
+
+			if length == -1 {
+				continue
+			}
+
+			if length > int64(len(data)-pos-2) {
+				return errIncompleteResp
+			}
+
+			pos += int(length)
+			if !bytes.HasPrefix(data[pos:], []byte("\r\n")) {
+				return fmt.Errorf("string without terminator: %w", ErrMalformedResponseReceived)
+			}
+			pos += 2
+
+		case '*', '%':
+			if isKey {
+				return fmt.Errorf("aggregate used as a map key: %w", ErrMalformedResponseReceived)
+			}
+
+			count, err := strconv.ParseInt(line, 10, 64)
+			if err != nil || count < -1 || (count == -1 && kind == '%') || (kind == '%' && count%2 != 0) {
+				return fmt.Errorf("invalid element count %q: %w", line, ErrMalformedResponseReceived)
+			}
+
+			if count == -1 {
+				continue
+			}
+
+			// every element takes at least three bytes
+			if count > int64(len(data)-pos)/3 {
+				return errIncompleteResp
+			}
+
+			if maxDepth > 0 && int64(len(levels)) > maxDepth {
+				return fmt.Errorf("reply nested deeper than %d levels: %w", maxDepth, ErrReplyTooDeep)
+			}
+
+			levels = append(levels, respLevel{remaining: count, isMap: kind == '%'})
+
+		default:
+			return fmt.Errorf("unsupported data type %q: %w", kind, ErrMalformedResponseReceived)
+		}
+	}
+
+	if pos != len(data) {
+		return fmt.Errorf("%d trailing bytes after the reply: %w", len(data)-pos, ErrMalformedResponseReceived)
+	}
+
+	return nil
+}
@@ -1,9 +1,16 @@
 package universum
 
+import "strconv"
+
 // Universum server response codes
 const (
 	RespPingSuccess     int64 = 200
 	RespSnapshotStarted int64 = 201
+	RespAuthSuccess     int64 = 202
+	RespHelloSuccess    int64 = 203
+
+	RespAuthRequired int64 = 401
+	RespAuthFailed   int64 = 403
 
 	RespServerShuttingDown int64 = 501
 	RespServerBusy         int64 = 502
@@ -27,3 +34,40 @@ const (
 	RespRecordTooBig     int64 = 5005
 	RespIinvalidDatatype int64 = 5006
 )
+
+// respCodeNames maps the response codes to the names of their constants
+var respCodeNames = map[int64]string{
+	RespPingSuccess:        "RespPingSuccess",
+	RespSnapshotStarted:    "RespSnapshotStarted",
+	RespAuthSuccess:        "RespAuthSuccess",
+	RespHelloSuccess:       "RespHelloSuccess",
+	RespAuthRequired:       "RespAuthRequired",
+	RespAuthFailed:         "RespAuthFailed",
+	RespServerShuttingDown: "RespServerShuttingDown",
+	RespServerBusy:         "RespServerBusy",
+	RespRecordFound:        "RespRecordFound",
+	RespRecordUpdated:      "RespRecordUpdated",
+	RespRecordDeleted:      "RespRecordDeleted",
+	RespHelpContentOk:      "RespHelpContentOk",
+	RespInfoContentOk:      "RespInfoContentOk",
+	RespMgetCompleted:      "RespMgetCompleted",
+	RespMsetCompleted:      "RespMsetCompleted",
+	RespMdelCompleted:      "RespMdelCompleted",
+	RespInvalidCmdInput:    "RespInvalidCmdInput",
+	RespRecordNotFound:     "RespRecordNotFound",
+	RespRecordExpired:      "RespRecordExpired",
+	RespRecordNotDeleted:   "RespRecordNotDeleted",
+	RespIncrInvalidType:    "RespIncrInvalidType",
+	RespRecordTooBig:       "RespRecordTooBig",
+	RespIinvalidDatatype:   "RespIinvalidDatatype",
+}
+
+// RespCodeName returns the name of the constant of a response code, e.g.
+// RespRecordFound for 1000, or the number itself for unknown codes.
+func RespCodeName(code int64) string {
+	if name, ok := respCodeNames[code]; ok {
+		return name
+	}
+
+	return strconv.FormatInt(code, 10)
+}
@@ -102,3 +102,13 @@ type InfoResult struct {
 	Raw  string
 	Code int64
 }
+
+type HelpResult struct {
+	Raw  string
+	Code int64
+}
+
+type SnapshotResult struct {
+	Started bool
+	Code    int64
+}
@@ -0,0 +1,183 @@
+package universum
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// SlowLogEntry describes a single command whose total execution time exceeded
+// the configured slow-log threshold. The total time is split into the phases
+// of a round trip so that the cause of the latency can be identified.
+type SlowLogEntry struct {
+	Command   string
+	Keys      []string
+	ArgSizes  []int
+	StartedAt time.Time
+
+	Duration    time.Duration
+	LimiterWait time.Duration
+	PoolWait    time.Duration
+	Write       time.Duration
+	Read        time.Duration
+	Decode      time.Duration
+
+	Err error
+}
+
+// commandTrace records how long each phase of a command round trip took.
+type commandTrace struct {
+	start       time.Time
+	limiterWait time.Duration
+	poolWait    time.Duration
+	write       time.Duration
+	read        time.Duration
+	decode      time.Duration
+}
+
+func newCommandTrace() *commandTrace {
+	return &commandTrace{start: time.Now()}
+}
+
+// slowLog is a bounded ring buffer of slow commands, optionally reporting
+// every recorded entry to a callback.
+type slowLog struct {
+	mu        sync.Mutex
+	threshold time.Duration
+	callback  func(SlowLogEntry)
+
+	entries []SlowLogEntry
+	next    int
+	full    bool
+}
+
+func newSlowLog(threshold time.Duration, maxLen int64, callback func(SlowLogEntry)) *slowLog {
+	return &slowLog{
+		threshold: threshold,
+		callback:  callback,
+		entries:   make([]SlowLogEntry, maxLen),
+	}
+}
+
+// observe records the command into the ring buffer if its total duration
+// crossed the threshold.
+func (sl *slowLog) observe(trace *commandTrace, command string, args []interface{}, err error) {
+	elapsed := time.Since(trace.start)
+	if elapsed < sl.threshold {
+		return
+	}
+
+	entry := SlowLogEntry{
+		Command:     command,
+		Keys:        commandKeys(command, args),
+		ArgSizes:    argSizes(args),
+		StartedAt:   trace.start,
+		Duration:    elapsed,
+		LimiterWait: trace.limiterWait,
+		PoolWait:    trace.poolWait,
+		Write:       trace.write,
+		Read:        trace.read,
+		Decode:      trace.decode,
+		Err:         err,
+	}
+
+	sl.add(entry)
+
+	if sl.callback != nil {
+		sl.callback(entry)
+	}
+}
+
+func (sl *slowLog) add(entry SlowLogEntry) {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	if len(sl.entries) == 0 {
+		return
+	}
+
+	sl.entries[sl.next] = entry
+	sl.next++
+
+	if sl.next == len(sl.entries) {
+		sl.next = 0
+		sl.full = true
+	}
+}
+
+// snapshot returns the recorded entries, newest first.
+func (sl *slowLog) snapshot() []SlowLogEntry {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	count := sl.next
+	if sl.full {
+		count = len(sl.entries)
+	}
+
+	result := make([]SlowLogEntry, 0, count)
+	for i := 1; i <= count; i++ {
+		index := (sl.next - i + len(sl.entries)) % len(sl.entries)
+		result = append(result, sl.entries[index])
+	}
+
+	return result
+}
+
+func (sl *slowLog) reset() {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	for i := range sl.entries {
+		sl.entries[i] = SlowLogEntry{}
+	}
+	sl.next = 0
+	sl.full = false
+}
+
+// commandKeys extracts the keys a command operates on from its arguments.
+func commandKeys(command string, args []interface{}) []string {
+	if len(args) == 0 {
+		return nil
+	}
+
+	switch command {
+	case commandPing, commandInfo, commandHelp, commandSnapshot:
+		return nil
+
+	case commandMget, commandMdelete:
+		if keys, ok := args[0].([]string); ok {
+			return append([]string(nil), keys...)
+		}
+
+	case commandMset:
+		if kv, ok := args[0].(map[string]interface{}); ok {
+			keys := make([]string, 0, len(kv))
+			for key := range kv {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			return keys
+		}
+
+	default:
+		if key, ok := args[0].(string); ok {
+			return []string{key}
+		}
+	}
+
+	return nil
+}
+
+// argSizes returns the encoded size in bytes of each command argument.
+func argSizes(args []interface{}) []int {
+	sizes := make([]int, len(args))
+
+	for i, arg := range args {
+		if encoded, err := encodeResp(arg); err == nil {
+			sizes[i] = len(encoded)
+		}
+	}
+
+	return sizes
+}
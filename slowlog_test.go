@@ -0,0 +1,121 @@
+package universum
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSlowLogRingBuffer(t *testing.T) {
+	sl := newSlowLog(0, 3, nil)
+
+	for _, command := range []string{"A", "B", "C", "D", "E"} {
+		sl.add(SlowLogEntry{Command: command})
+	}
+
+	entries := sl.snapshot()
+	commands := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		commands = append(commands, entry.Command)
+	}
+
+	if !reflect.DeepEqual(commands, []string{"E", "D", "C"}) {
+		t.Fatalf("Expected newest three entries first, got %v", commands)
+	}
+
+	sl.reset()
+	if len(sl.snapshot()) != 0 {
+		t.Fatalf("Expected slow log to be empty after reset")
+	}
+}
+
+func TestCommandKeys(t *testing.T) {
+	testCases := []struct {
+		name     string
+		command  string
+		args     []interface{}
+		expected []string
+	}{
+		{"Single key", commandGet, []interface{}{"foo"}, []string{"foo"}},
+		{"Multi key", commandMget, []interface{}{[]string{"a", "b"}}, []string{"a", "b"}},
+		{"Map keys", commandMset, []interface{}{map[string]interface{}{"y": 1, "x": 2}}, []string{"x", "y"}},
+		{"Keyless", commandPing, nil, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if keys := commandKeys(tc.command, tc.args); !reflect.DeepEqual(keys, tc.expected) {
+				t.Errorf("Expected keys %v, got %v", tc.expected, keys)
+			}
+		})
+	}
+}
+
+func TestClient_SlowLog(t *testing.T) {
+	var mu sync.Mutex
+	var reported []SlowLogEntry
+
+	client := newFakeClient(t, func(cmd []interface{}) interface{} {
+		if cmd[0] == commandGet && cmd[1] == "slow" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		return fakeReply(nil, RespRecordNotFound, "")
+	}, func(opts *Options) {
+		opts.SlowLogThreshold = 25 * time.Millisecond
+		opts.OnSlowCommand = func(entry SlowLogEntry) {
+			mu.Lock()
+			reported = append(reported, entry)
+			mu.Unlock()
+		}
+	})
+
+	ctx := context.Background()
+	if _, err := client.Get(ctx, "fast"); err != nil {
+		t.Fatalf("Expected no error from Get, got %v", err)
+	}
+	if _, err := client.Get(ctx, "slow"); err != nil {
+		t.Fatalf("Expected no error from Get, got %v", err)
+	}
+
+	entries := client.SlowLog()
+	if len(entries) != 1 {
+		t.Fatalf("Expected exactly one slow entry, got %d", len(entries))
+	}
+
+	entry := entries[0]
+	if entry.Command != commandGet || !reflect.DeepEqual(entry.Keys, []string{"slow"}) {
+		t.Errorf("Unexpected slow entry recorded: %+v", entry)
+	}
+	if len(entry.ArgSizes) != 1 || entry.ArgSizes[0] == 0 {
+		t.Errorf("Expected argument sizes to be recorded, got %v", entry.ArgSizes)
+	}
+	if entry.Read < 25*time.Millisecond || entry.Duration < entry.Read {
+		t.Errorf("Expected read phase to dominate the duration, got %+v", entry)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 1 || reported[0].Command != commandGet {
+		t.Errorf("Expected callback to receive the slow entry, got %v", reported)
+	}
+}
+
+func TestClient_SlowLogDisabled(t *testing.T) {
+	client, err := NewClient(&Options{})
+	if err != nil {
+		t.Fatalf("Expected no error while creating client, got %v", err)
+	}
+
+	if client.SlowLog() != nil {
+		t.Fatal("Expected nil slow log when threshold is not configured")
+	}
+
+	client.pool.Close()
+	_, err = client.Get(context.Background(), "key")
+	if !errors.Is(err, ErrConnectionPoolClosed) {
+		t.Fatalf("Expected pool closed error, got %v", err)
+	}
+}
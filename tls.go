@@ -0,0 +1,327 @@
+package universum
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// fileStamp identifies a version of a file on disk
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// tlsProvider builds the TLS configuration once and hands the cached copy to
+// every dial. When the material is read from files it polls their modification
+// times and atomically swaps in a freshly loaded configuration, calling
+// onRotate so that connections using the previous certificates are recycled.
+// A rotation which fails to load, e.g. because a file is only partially written,
+// keeps the previous configuration and is retried on the next poll.
+type tlsProvider struct {
+	opts     *Options
+	config   atomic.Pointer[tls.Config]
+	onRotate func()
+
+	stamps    map[string]fileStamp
+	lastErr   atomic.Pointer[error]
+	stopOnce  sync.Once
+	stopWatch chan struct{}
+	watchDone chan struct{}
+}
+
+// newTLSProvider loads the TLS configuration described by opts, returning a nil
+// provider if TLS is disabled. The files are watched for changes if
+// TLSReloadInterval is positive.
+func newTLSProvider(opts *Options, onRotate func()) (*tlsProvider, error) {
+	if !opts.EnableTLS {
+		return nil, nil
+	}
+
+	provider := &tlsProvider{
+		opts:     opts,
+		onRotate: onRotate,
+		stamps:   statTLSFiles(opts),
+	}
+
+	config, err := buildTLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	provider.config.Store(config)
+
+	if len(provider.stamps) > 0 && opts.TLSReloadInterval > 0 {
+		provider.stopWatch = make(chan struct{})
+		provider.watchDone = make(chan struct{})
+		go provider.watch()
+	}
+
+	return provider, nil
+}
+
+// current returns the TLS configuration for new connections, nil if TLS is disabled
+func (p *tlsProvider) current() *tls.Config {
+	if p == nil {
+		return nil
+	}
+
+	return p.config.Load()
+}
+
+// reloadErr returns the error of the last failed reload, nil if it succeeded
+func (p *tlsProvider) reloadErr() error {
+	if p == nil {
+		return nil
+	}
+
+	if err := p.lastErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// stop terminates the file watcher, if any
+func (p *tlsProvider) stop() {
+	if p == nil || p.stopWatch == nil {
+		return
+	}
+
+	p.stopOnce.Do(func() {
+		close(p.stopWatch)
+		<-p.watchDone
+	})
+}
+
+// watch polls the TLS files until the provider is stopped
+func (p *tlsProvider) watch() {
+	defer close(p.watchDone)
+
+	ticker := time.NewTicker(p.opts.TLSReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stopWatch:
+			return
+		case <-ticker.C:
+			p.reloadIfChanged()
+		}
+	}
+}
+
+// reloadIfChanged rebuilds the configuration if any of the files changed since
+// it was last loaded, and reports whether a new configuration was swapped in.
+func (p *tlsProvider) reloadIfChanged() bool {
+	stamps := statTLSFiles(p.opts)
+
+	changed := false
+	for path, stamp := range stamps {
+		if previous, ok := p.stamps[path]; !ok || !previous.modTime.Equal(stamp.modTime) || previous.size != stamp.size {
+			changed = true
+			break
+		}
+	}
+
+	if !changed {
+		return false
+	}
+
+	config, err := buildTLSConfig(p.opts)
+	if err != nil {
+		err = fmt.Errorf("failed to reload the rotated TLS files, keeping the previous certificates: %w", err)
+		p.lastErr.Store(&err)
+		return false
+	}
+
+	p.stamps = stamps
+	p.lastErr.Store(nil)
+	p.config.Store(config)
+
+	if p.onRotate != nil {
+		p.onRotate()
+	}
+
+	return true
+}
+
+// statTLSFiles returns the current stamps of the TLS files configured in opts,
+// files which cannot be read are left out and thus count as changed once they reappear
+func statTLSFiles(opts *Options) map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+
+	for _, path := range []string{opts.TLSCertFile, opts.TLSKeyFile, opts.CAFile} {
+		if path == "" {
+			continue
+		}
+
+		if info, err := os.Stat(path); err == nil {
+			stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+
+	return stamps
+}
+
+// tlsVersions maps the textual TLS versions accepted in configuration to their identifiers
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// parseTLSVersion parses a TLS version such as 1.2, also accepting the TLS prefix as in TLS1.2
+func parseTLSVersion(value string) (uint16, bool) {
+	value = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(value)), "TLS")
+	version, ok := tlsVersions[strings.TrimSpace(value)]
+	return version, ok
+}
+
+// formatTLSVersion renders a TLS version identifier, or an empty string if it is unknown
+func formatTLSVersion(version uint16) string {
+	for name, id := range tlsVersions {
+		if id == version {
+			return name
+		}
+	}
+	return ""
+}
+
+// cipherSuiteNames maps the crypto/tls cipher suite names to their identifiers
+func cipherSuiteNames() map[string]uint16 {
+	names := make(map[string]uint16)
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		names[suite.Name] = suite.ID
+	}
+	return names
+}
+
+// parseCipherSuites parses a comma-separated list of cipher suite names such
+// as TLS_AES_128_GCM_SHA256, also accepting hexadecimal identifiers
+func parseCipherSuites(value string) ([]uint16, bool) {
+	names := cipherSuiteNames()
+
+	var suites []uint16
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if id, ok := names[strings.ToUpper(name)]; ok {
+			suites = append(suites, id)
+			continue
+		}
+
+		id, err := strconv.ParseUint(name, 0, 16)
+		if err != nil || !strings.HasPrefix(strings.ToLower(name), "0x") {
+			return nil, false
+		}
+		suites = append(suites, uint16(id))
+	}
+
+	return suites, true
+}
+
+// formatCipherSuites renders cipher suites as a comma-separated list of names,
+// falling back to hexadecimal identifiers for suites unknown to crypto/tls
+func formatCipherSuites(suites []uint16) string {
+	parts := make([]string, len(suites))
+	for i, id := range suites {
+		parts[i] = fmt.Sprintf("%#04x", id)
+		for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+			if suite.ID == id {
+				parts[i] = suite.Name
+				break
+			}
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// validateCipherSuites reports cipher suite identifiers unknown to crypto/tls
+func validateCipherSuites(suites []uint16) error {
+	known := make(map[uint16]bool)
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		known[suite.ID] = true
+	}
+
+	for _, id := range suites {
+		if !known[id] {
+			return optionError("TLSCipherSuites", "TLSCipherSuites contains the unknown cipher suite %#04x: %w", id, ErrInvalidOption)
+		}
+	}
+
+	return nil
+}
+
+// buildTLSConfig assembles the TLS configuration from opts. Options.TLSConfig is
+// used as the base if set, with the server name, minimum version, cipher suites
+// and InsecureSkipVerify of opts taking precedence when they are set. A client certificate is
+// only presented for mutual TLS, taken from the GetClientCertificate callback,
+// the in-memory PEM bytes or the files, in that order. The server certificate
+// is verified against CAPEM or CAFile, falling back to the system roots.
+func buildTLSConfig(opts *Options) (*tls.Config, error) {
+	config := &tls.Config{}
+	if opts.TLSConfig != nil {
+		config = opts.TLSConfig.Clone()
+	}
+
+	// Option to skip server cert verification (not recommended)
+	if opts.InsecureSkipVerify {
+		config.InsecureSkipVerify = true
+	}
+
+	if opts.TLSServerName != "" {
+		config.ServerName = opts.TLSServerName
+	}
+
+	// the minimum version is only ever raised, so a stricter TLSConfig is kept
+	config.MinVersion = max(config.MinVersion, opts.TLSMinVersion)
+	if config.MinVersion == 0 {
+		config.MinVersion = DefaultTLSMinVersion
+	}
+
+	if len(opts.TLSCipherSuites) > 0 {
+		config.CipherSuites = opts.TLSCipherSuites
+	}
+
+	switch {
+	case opts.GetClientCertificate != nil:
+		config.GetClientCertificate = opts.GetClientCertificate
+
+	case len(opts.TLSCertPEM) > 0 || len(opts.TLSKeyPEM) > 0:
+		cert, err := tls.X509KeyPair(opts.TLSCertPEM, opts.TLSKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse TLS certificate and key: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+
+	case opts.TLSCertFile != "" || opts.TLSKeyFile != "":
+		cert, err := tls.LoadX509KeyPair(opts.TLSCertFile, opts.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS certificate and key: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	caPEM := opts.CAPEM
+	if len(caPEM) == 0 && opts.CAFile != "" {
+		var err error
+		if caPEM, err = os.ReadFile(opts.CAFile); err != nil {
+			return nil, fmt.Errorf("failed to load CA certificate: %w", err)
+		}
+	}
+
+	if len(caPEM) > 0 {
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("failed to parse CA certificate: no certificates found")
+		}
+		config.RootCAs = caCertPool
+	}
+
+	return config, nil
+}
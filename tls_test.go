@@ -0,0 +1,543 @@
+package universum
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCA is a throwaway certificate authority used to issue test certificates
+type testCA struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate CA key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "universum test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create CA certificate: %v", err)
+	}
+
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// issue creates a certificate for the given host name or IP address, valid for
+// both server and client authentication
+func (ca *testCA) issue(t *testing.T, name string) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	if ip := net.ParseIP(name); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else {
+		template.DNSNames = []string{name}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeTLSFiles writes the certificate, key and CA into dir, bumping their
+// modification time so that a rewrite within the same second is noticed
+func writeTLSFiles(t *testing.T, dir string, certPEM, keyPEM, caPEM []byte, modTime time.Time) (string, string, string) {
+	paths := []string{filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"), filepath.Join(dir, "ca.crt")}
+
+	for i, content := range [][]byte{certPEM, keyPEM, caPEM} {
+		if err := os.WriteFile(paths[i], content, 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", paths[i], err)
+		}
+		if err := os.Chtimes(paths[i], modTime, modTime); err != nil {
+			t.Fatalf("Failed to touch %s: %v", paths[i], err)
+		}
+	}
+
+	return paths[0], paths[1], paths[2]
+}
+
+func TestBuildTLSConfig_PEM(t *testing.T) {
+	ca := newTestCA(t)
+	certPEM, keyPEM := ca.issue(t, "client")
+
+	config, err := buildTLSConfig(&Options{EnableTLS: true, TLSCertPEM: certPEM, TLSKeyPEM: keyPEM, CAPEM: ca.certPEM})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(config.Certificates) != 1 || config.RootCAs == nil {
+		t.Fatalf("Expected certificate and root CAs to be set, got %+v", config)
+	}
+
+	_, err = buildTLSConfig(&Options{EnableTLS: true, TLSCertPEM: certPEM, TLSKeyPEM: keyPEM, CAPEM: []byte("garbage")})
+	if err == nil {
+		t.Fatal("Expected an error for an unparsable CA")
+	}
+}
+
+func TestBuildTLSConfig_MinVersion(t *testing.T) {
+	opts := &Options{EnableTLS: true, TLSConfig: &tls.Config{MinVersion: tls.VersionTLS13}}
+	opts.Init()
+
+	config, err := buildTLSConfig(opts)
+	if err != nil || config.MinVersion != tls.VersionTLS13 {
+		t.Fatalf("Expected the caller's TLS 1.3 minimum to be kept, got %#04x, %v", config.MinVersion, err)
+	}
+
+	opts.TLSMinVersion = tls.VersionTLS12
+	if config, err = buildTLSConfig(opts); err != nil || config.MinVersion != tls.VersionTLS13 {
+		t.Fatalf("Expected a lower TLSMinVersion not to weaken the TLSConfig, got %#04x, %v", config.MinVersion, err)
+	}
+
+	config, err = buildTLSConfig(&Options{EnableTLS: true, TLSConfig: &tls.Config{}, TLSMinVersion: tls.VersionTLS13})
+	if err != nil || config.MinVersion != tls.VersionTLS13 {
+		t.Fatalf("Expected TLSMinVersion to raise the minimum, got %#04x, %v", config.MinVersion, err)
+	}
+
+	if config, err = buildTLSConfig(&Options{EnableTLS: true}); err != nil || config.MinVersion != DefaultTLSMinVersion {
+		t.Fatalf("Expected the default minimum version, got %#04x, %v", config.MinVersion, err)
+	}
+}
+
+func TestBuildTLSConfig_ConfigAndCallback(t *testing.T) {
+	base := &tls.Config{ServerName: "db.internal", MinVersion: tls.VersionTLS13}
+	called := false
+
+	config, err := buildTLSConfig(&Options{
+		EnableTLS: true,
+		TLSConfig: base,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			called = true
+			return &tls.Certificate{}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if config == base || config.ServerName != "db.internal" || config.MinVersion != tls.VersionTLS13 {
+		t.Fatalf("Expected a clone of the provided config, got %+v", config)
+	}
+
+	if _, err := config.GetClientCertificate(nil); err != nil || !called {
+		t.Fatal("Expected the client certificate callback to be installed")
+	}
+
+	if base.GetClientCertificate != nil {
+		t.Fatal("Expected the provided config to be left untouched")
+	}
+}
+
+func TestBuildTLSConfig_InsecureSkipVerifyWithConfig(t *testing.T) {
+	base := &tls.Config{ServerName: "db.internal"}
+
+	config, err := buildTLSConfig(&Options{EnableTLS: true, TLSConfig: base, InsecureSkipVerify: true})
+	if err != nil || !config.InsecureSkipVerify {
+		t.Fatalf("Expected InsecureSkipVerify to apply on top of the provided config, got %+v, %v", config, err)
+	}
+	if base.InsecureSkipVerify {
+		t.Fatal("Expected the provided config to be left untouched")
+	}
+
+	base.InsecureSkipVerify = true
+	if config, err = buildTLSConfig(&Options{EnableTLS: true, TLSConfig: base}); err != nil || !config.InsecureSkipVerify {
+		t.Fatalf("Expected the provided InsecureSkipVerify to be kept, got %+v, %v", config, err)
+	}
+}
+
+func TestTLSProvider_Rotation(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	modTime := time.Now().Add(-time.Minute)
+
+	certPEM, keyPEM := ca.issue(t, "client")
+	certFile, keyFile, caFile := writeTLSFiles(t, dir, certPEM, keyPEM, ca.certPEM, modTime)
+
+	opts := &Options{EnableTLS: true, TLSCertFile: certFile, TLSKeyFile: keyFile, CAFile: caFile, TLSReloadInterval: -1}
+	rotations := 0
+
+	provider, err := newTLSProvider(opts, func() { rotations++ })
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer provider.stop()
+
+	initial := provider.current()
+	if provider.reloadIfChanged() || provider.current() != initial {
+		t.Fatal("Expected no reload while the files are unchanged")
+	}
+
+	// a partially written key must not replace the working configuration
+	writeTLSFiles(t, dir, certPEM, keyPEM[:len(keyPEM)/2], ca.certPEM, modTime.Add(time.Second))
+	if provider.reloadIfChanged() || provider.current() != initial || rotations != 0 {
+		t.Fatal("Expected a broken rotation to keep the previous configuration")
+	}
+	if provider.reloadErr() == nil {
+		t.Fatal("Expected the failed reload to be reported")
+	}
+
+	rotatedCert, rotatedKey := ca.issue(t, "client")
+	writeTLSFiles(t, dir, rotatedCert, rotatedKey, ca.certPEM, modTime.Add(2*time.Second))
+	if !provider.reloadIfChanged() || rotations != 1 {
+		t.Fatal("Expected the rotated certificate to be loaded")
+	}
+
+	rotated := provider.current()
+	if rotated == initial || provider.reloadErr() != nil {
+		t.Fatal("Expected the new configuration to be swapped in")
+	}
+
+	leaf, _ := x509.ParseCertificate(rotated.Certificates[0].Certificate[0])
+	block, _ := pem.Decode(rotatedCert)
+	if leaf.SerialNumber.Cmp(mustParseCert(t, block.Bytes).SerialNumber) != 0 {
+		t.Fatal("Expected the configuration to hold the rotated certificate")
+	}
+}
+
+func TestTLSProvider_Watch(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	modTime := time.Now().Add(-time.Minute)
+
+	certPEM, keyPEM := ca.issue(t, "client")
+	certFile, keyFile, caFile := writeTLSFiles(t, dir, certPEM, keyPEM, ca.certPEM, modTime)
+
+	rotated := make(chan struct{}, 1)
+	opts := &Options{EnableTLS: true, TLSCertFile: certFile, TLSKeyFile: keyFile, CAFile: caFile,
+		TLSReloadInterval: MinTLSReloadInterval}
+
+	provider, err := newTLSProvider(opts, func() { rotated <- struct{}{} })
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer provider.stop()
+
+	rotatedCert, rotatedKey := ca.issue(t, "client")
+	writeTLSFiles(t, dir, rotatedCert, rotatedKey, ca.certPEM, modTime.Add(time.Second))
+
+	select {
+	case <-rotated:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the watcher to pick up the rotated files")
+	}
+}
+
+func TestClient_TLSReloadErr(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	modTime := time.Now().Add(-time.Minute)
+
+	certPEM, keyPEM := ca.issue(t, "client")
+	certFile, keyFile, caFile := writeTLSFiles(t, dir, certPEM, keyPEM, ca.certPEM, modTime)
+
+	opts := mockOptions()
+	opts.EnableTLS = true
+	opts.TLSCertFile, opts.TLSKeyFile, opts.CAFile = certFile, keyFile, caFile
+	opts.TLSReloadInterval = MinTLSReloadInterval
+
+	client, err := NewClient(opts)
+	if err != nil {
+		t.Fatalf("Expected no error while creating client, got %v", err)
+	}
+	defer client.Close()
+
+	if err := client.TLSReloadErr(); err != nil {
+		t.Fatalf("Expected no reload error, got %v", err)
+	}
+
+	waitFor := func(failed bool) error {
+		deadline := time.Now().Add(5 * time.Second)
+		for (client.TLSReloadErr() != nil) != failed && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		return client.TLSReloadErr()
+	}
+
+	writeTLSFiles(t, dir, certPEM, keyPEM[:len(keyPEM)/2], ca.certPEM, modTime.Add(time.Second))
+	if err := waitFor(true); err == nil || !strings.Contains(err.Error(), "keeping the previous certificates") {
+		t.Fatalf("Expected the failed rotation to be reported, got %v", err)
+	}
+
+	rotatedCert, rotatedKey := ca.issue(t, "client")
+	writeTLSFiles(t, dir, rotatedCert, rotatedKey, ca.certPEM, modTime.Add(2*time.Second))
+	if err := waitFor(false); err != nil {
+		t.Fatalf("Expected the reload error to clear after a successful rotation, got %v", err)
+	}
+}
+
+func TestConnPool_DrainRecyclesConns(t *testing.T) {
+	pool, err := newConnPool(mockPoolOptions(t), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	idle, _ := pool.GetConn(ctx)
+	busy, _ := pool.GetConn(ctx)
+	pool.ReleaseConn(ctx, idle)
+
+	pool.drain(time.Now())
+
+	if pool.Len() != 1 || pool.IdleLen() != 0 {
+		t.Fatalf("Expected idle connection to be closed, got %d live and %d idle", pool.Len(), pool.IdleLen())
+	}
+
+	pool.ReleaseConn(ctx, busy)
+	if pool.Len() != 0 {
+		t.Fatalf("Expected checked-out connection to be closed on release, got %d live", pool.Len())
+	}
+
+	fresh, err := pool.GetConn(ctx)
+	if err != nil || fresh == idle || fresh == busy {
+		t.Fatalf("Expected a freshly dialed connection, got %v", err)
+	}
+	pool.ReleaseConn(ctx, fresh)
+}
+
+func mustParseCert(t *testing.T, der []byte) *x509.Certificate {
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+	return cert
+}
+
+// newTLSTestServer starts a fake TLS server presenting a certificate for name
+// issued by ca, letting configure adjust the server configuration
+func newTLSTestServer(t *testing.T, ca *testCA, name string, configure func(*tls.Config)) *fakeServer {
+	certPEM, keyPEM := ca.issue(t, name)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("Failed to load server certificate: %v", err)
+	}
+
+	config := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if configure != nil {
+		configure(config)
+	}
+
+	return newFakeTLSServer(t, config, func(cmd []interface{}) interface{} {
+		return fakeReply("PONG", RespPingSuccess, "OK")
+	})
+}
+
+// dialTLS dials the server once with the TLS configuration built from opts
+func dialTLS(t *testing.T, server *fakeServer, opts *Options) error {
+	opts.HostAddr = server.addr()
+	opts.EnableTLS = true
+	opts.MaxRetries = 1
+	opts.Init()
+
+	config, err := buildTLSConfig(opts)
+	if err != nil {
+		t.Fatalf("Expected no error building TLS config, got %v", err)
+	}
+
+	conn, err := newConnection(context.Background(), opts, config)
+	if err != nil {
+		return err
+	}
+	defer conn.close()
+
+	_, err = execOnConn(context.Background(), conn, opts, newCommandTrace(), commandPing)
+	return err
+}
+
+func TestBuildTLSConfig_ServerOnly(t *testing.T) {
+	config, err := buildTLSConfig(&Options{EnableTLS: true, TLSServerName: "db.internal",
+		TLSMinVersion: tls.VersionTLS13, TLSCipherSuites: []uint16{tls.TLS_AES_128_GCM_SHA256}})
+	if err != nil {
+		t.Fatalf("Expected no client certificate to be required, got %v", err)
+	}
+
+	if len(config.Certificates) != 0 || config.GetClientCertificate != nil {
+		t.Fatal("Expected no client certificate for server-only TLS")
+	}
+	if config.RootCAs != nil {
+		t.Fatal("Expected the system roots to be used without a CA")
+	}
+	if config.ServerName != "db.internal" || config.MinVersion != tls.VersionTLS13 || len(config.CipherSuites) != 1 {
+		t.Fatalf("Expected server name, min version and cipher suites to be applied, got %+v", config)
+	}
+}
+
+func TestTLSHandshake_ServerOnly(t *testing.T) {
+	ca := newTestCA(t)
+	server := newTLSTestServer(t, ca, "127.0.0.1", nil)
+
+	if err := dialTLS(t, server, &Options{CAPEM: ca.certPEM}); err != nil {
+		t.Fatalf("Expected server-only TLS to succeed, got %v", err)
+	}
+
+	err := dialTLS(t, server, &Options{CAPEM: newTestCA(t).certPEM})
+	if !errors.Is(err, ErrConnectionDialFailed) {
+		t.Fatalf("Expected a certificate from an unknown CA to be rejected, got %v", err)
+	}
+}
+
+func TestTLSHandshake_MutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	server := newTLSTestServer(t, ca, "127.0.0.1", func(config *tls.Config) {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		config.ClientCAs = x509.NewCertPool()
+		config.ClientCAs.AddCert(ca.cert)
+	})
+
+	certPEM, keyPEM := ca.issue(t, "client")
+	if err := dialTLS(t, server, &Options{CAPEM: ca.certPEM, TLSCertPEM: certPEM, TLSKeyPEM: keyPEM}); err != nil {
+		t.Fatalf("Expected mutual TLS to succeed, got %v", err)
+	}
+
+	if err := dialTLS(t, server, &Options{CAPEM: ca.certPEM}); err == nil {
+		t.Fatal("Expected the server to reject a client without certificate")
+	}
+}
+
+func TestTLSHandshake_ServerName(t *testing.T) {
+	ca := newTestCA(t)
+	server := newTLSTestServer(t, ca, "db.internal", nil)
+
+	if err := dialTLS(t, server, &Options{CAPEM: ca.certPEM}); err == nil {
+		t.Fatal("Expected verification against the dialed IP address to fail")
+	}
+
+	if err := dialTLS(t, server, &Options{CAPEM: ca.certPEM, TLSServerName: "db.internal"}); err != nil {
+		t.Fatalf("Expected the explicit server name to be verified, got %v", err)
+	}
+}
+
+func TestTLSHandshake_MinVersion(t *testing.T) {
+	ca := newTestCA(t)
+	server := newTLSTestServer(t, ca, "127.0.0.1", func(config *tls.Config) {
+		config.MaxVersion = tls.VersionTLS12
+	})
+
+	if err := dialTLS(t, server, &Options{CAPEM: ca.certPEM}); err != nil {
+		t.Fatalf("Expected TLS 1.2 to be accepted by default, got %v", err)
+	}
+
+	if err := dialTLS(t, server, &Options{CAPEM: ca.certPEM, TLSMinVersion: tls.VersionTLS13}); err == nil {
+		t.Fatal("Expected a TLS 1.2 server to be rejected with a TLS 1.3 minimum")
+	}
+}
+
+func TestTLSHandshake_Timeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	// accept connections but never answer the handshake
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	opts := &Options{HostAddr: listener.Addr().String(), EnableTLS: true, DialTimeout: 200 * time.Millisecond, MaxRetries: 1}
+	opts.Init()
+
+	start := time.Now()
+	_, err = newConnection(context.Background(), opts, &tls.Config{InsecureSkipVerify: true})
+
+	if !errors.Is(err, ErrConnectionDialTimeout) {
+		t.Fatalf("Expected dial timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected the handshake to be bound by the dial timeout, took %s", elapsed)
+	}
+}
+
+func TestTLSOptions_Validation(t *testing.T) {
+	opts, err := ParseURL("universums://localhost:11191?tls_server_name=db.internal&tls_min_version=1.3")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if opts.TLSServerName != "db.internal" || opts.TLSMinVersion != tls.VersionTLS13 {
+		t.Fatalf("Expected TLS options from url, got %q %#04x", opts.TLSServerName, opts.TLSMinVersion)
+	}
+
+	if _, err := ParseURL("universums://localhost:11191?tls_min_version=1.4"); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("Expected an unknown TLS version to be rejected, got %v", err)
+	}
+
+	err = (&Options{TLSMinVersion: 0x0999, TLSCipherSuites: []uint16{0xffff}}).Validate()
+	if err == nil || !strings.Contains(err.Error(), "TLSMinVersion") || !strings.Contains(err.Error(), "TLSCipherSuites") {
+		t.Fatalf("Expected TLS version and cipher suites to be reported, got %v", err)
+	}
+
+	suites := []uint16{tls.TLS_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}
+	opts, err = ParseURL("universums://localhost:11191?tls_cipher_suites=TLS_AES_128_GCM_SHA256,TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256")
+	if err != nil || !reflect.DeepEqual(opts.TLSCipherSuites, suites) {
+		t.Fatalf("Expected cipher suites from url, got %v, %v", opts, err)
+	}
+	if reparsed, err := ParseURL(opts.String()); err != nil || !reflect.DeepEqual(reparsed.TLSCipherSuites, suites) {
+		t.Fatalf("Expected cipher suites to survive rendering, got %v, %v", reparsed, err)
+	}
+
+	t.Setenv("UNIVERSUM_TLS_CIPHER_SUITES", "0xffff")
+	if _, err := OptionsFromEnv(""); err == nil || !strings.HasPrefix(err.Error(), "UNIVERSUM_TLS_CIPHER_SUITES: ") {
+		t.Fatalf("Expected the unknown cipher suite to be reported by variable, got %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+package universummock
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cshekharsharma/universum-client-go"
+)
+
+// ErrUnmetExpectations is returned by ExpectationsWereMet when expected
+// commands were never called.
+var ErrUnmetExpectations = errors.New("UNMET_EXPECTATIONS")
+
+// expectation is a command expected to be called with the given arguments
+type expectation struct {
+	command string
+	args    []interface{}
+
+	result interface{}
+	err    error
+	canned bool
+	met    bool
+}
+
+func (e *expectation) String() string {
+	if len(e.args) == 0 {
+		return e.command
+	}
+
+	args := make([]string, 0, len(e.args))
+	for _, arg := range e.args {
+		args = append(args, fmt.Sprintf("%v", arg))
+	}
+
+	return e.command + " " + strings.Join(args, " ")
+}
+
+// Expectation is a command expected to be called on the mock. It is answered
+// by the in-memory fake unless a result or an error is set.
+type Expectation[R any] struct {
+	mock *Mock
+	e    *expectation
+}
+
+// Return answers the expected command with result instead of the fake.
+func (x *Expectation[R]) Return(result R) {
+	x.mock.mu.Lock()
+	defer x.mock.mu.Unlock()
+
+	x.e.result = result
+	x.e.err = nil
+	x.e.canned = true
+}
+
+// ReturnError fails the expected command with err.
+func (x *Expectation[R]) ReturnError(err error) {
+	x.mock.mu.Lock()
+	defer x.mock.mu.Unlock()
+
+	var zero R
+	x.e.result = zero
+	x.e.err = err
+	x.e.canned = true
+}
+
+// expect registers an expectation for the command and its arguments
+func expect[R any](m *Mock, command string, args ...interface{}) *Expectation[R] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := &expectation{command: command, args: args}
+	m.expectations = append(m.expectations, e)
+
+	return &Expectation[R]{mock: m, e: e}
+}
+
+// match marks the first unmet expectation for the command and arguments as
+// met, and returns it if it has a canned answer
+func (m *Mock) match(command string, args ...interface{}) *expectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.expectations {
+		if e.met || e.command != command || !reflect.DeepEqual(e.args, args) {
+			continue
+		}
+
+		e.met = true
+		if e.canned {
+			return e
+		}
+		return nil
+	}
+
+	return nil
+}
+
+// respond returns the canned answer of an expectation
+func respond[R any](e *expectation) (R, error) {
+	result, _ := e.result.(R)
+	return result, e.err
+}
+
+// ExpectationsWereMet returns an error listing the expected commands which
+// were not called.
+func (m *Mock) ExpectationsWereMet() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var unmet []string
+	for _, e := range m.expectations {
+		if !e.met {
+			unmet = append(unmet, e.String())
+		}
+	}
+
+	if len(unmet) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%d expected commands were not called [%s]: %w",
+		len(unmet), strings.Join(unmet, ", "), ErrUnmetExpectations)
+}
+
+// AssertExpectations reports the expected commands which were not called as
+// test errors.
+func (m *Mock) AssertExpectations(t testing.TB) {
+	t.Helper()
+
+	if err := m.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// ClearExpectations removes all expectations, met or not.
+func (m *Mock) ClearExpectations() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expectations = nil
+}
+
+// ExpectGet expects Get to be called with the key.
+func (m *Mock) ExpectGet(key string) *Expectation[*universum.GetResult] {
+	return expect[*universum.GetResult](m, commandGet, key)
+}
+
+// ExpectSet expects Set to be called with the key, value and TTL.
+func (m *Mock) ExpectSet(key string, value interface{}, ttl int64) *Expectation[*universum.SetResult] {
+	return expect[*universum.SetResult](m, commandSet, key, value, ttl)
+}
+
+// ExpectExists expects Exists to be called with the key.
+func (m *Mock) ExpectExists(key string) *Expectation[*universum.ExistsResult] {
+	return expect[*universum.ExistsResult](m, commandExists, key)
+}
+
+// ExpectDelete expects Delete to be called with the key.
+func (m *Mock) ExpectDelete(key string) *Expectation[*universum.DeleteResult] {
+	return expect[*universum.DeleteResult](m, commandDelete, key)
+}
+
+// ExpectIncrement expects Increment to be called with the key and offset.
+func (m *Mock) ExpectIncrement(key string, offset int64) *Expectation[*universum.IncrementResult] {
+	return expect[*universum.IncrementResult](m, commandIncr, key, offset)
+}
+
+// ExpectDecrement expects Decrement to be called with the key and offset.
+func (m *Mock) ExpectDecrement(key string, offset int64) *Expectation[*universum.DecrementResult] {
+	return expect[*universum.DecrementResult](m, commandDecr, key, offset)
+}
+
+// ExpectAppend expects Append to be called with the key and value.
+func (m *Mock) ExpectAppend(key string, value string) *Expectation[*universum.AppendResult] {
+	return expect[*universum.AppendResult](m, commandAppend, key, value)
+}
+
+// ExpectMGet expects MGet to be called with the keys, in the same order.
+func (m *Mock) ExpectMGet(keys []string) *Expectation[*universum.MGetResult] {
+	return expect[*universum.MGetResult](m, commandMget, keys)
+}
+
+// ExpectMSet expects MSet to be called with the key-value pairs.
+func (m *Mock) ExpectMSet(kv map[string]interface{}) *Expectation[*universum.MSetResult] {
+	return expect[*universum.MSetResult](m, commandMset, kv)
+}
+
+// ExpectMDelete expects MDelete to be called with the keys, in the same order.
+func (m *Mock) ExpectMDelete(keys []string) *Expectation[*universum.MDeleteResult] {
+	return expect[*universum.MDeleteResult](m, commandMdelete, keys)
+}
+
+// ExpectTTL expects TTL to be called with the key.
+func (m *Mock) ExpectTTL(key string) *Expectation[*universum.TTLResult] {
+	return expect[*universum.TTLResult](m, commandTtl, key)
+}
+
+// ExpectExpire expects Expire to be called with the key and TTL.
+func (m *Mock) ExpectExpire(key string, ttl int64) *Expectation[*universum.ExpireResult] {
+	return expect[*universum.ExpireResult](m, commandExpire, key, ttl)
+}
+
+// ExpectPing expects Ping to be called.
+func (m *Mock) ExpectPing() *Expectation[*universum.PingResult] {
+	return expect[*universum.PingResult](m, commandPing)
+}
+
+// ExpectInfo expects Info to be called.
+func (m *Mock) ExpectInfo() *Expectation[*universum.InfoResult] {
+	return expect[*universum.InfoResult](m, commandInfo)
+}
+
+// ExpectHelp expects Help to be called.
+func (m *Mock) ExpectHelp() *Expectation[*universum.HelpResult] {
+	return expect[*universum.HelpResult](m, commandHelp)
+}
+
+// ExpectSnapshot expects Snapshot to be called.
+func (m *Mock) ExpectSnapshot() *Expectation[*universum.SnapshotResult] {
+	return expect[*universum.SnapshotResult](m, commandSnapshot)
+}
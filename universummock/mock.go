@@ -0,0 +1,410 @@
+// Package universummock provides an in-memory fake of the Universum client
+// implementing universum.Cmdable, for unit tests without sockets.
+//
+// Commands are served by an in-memory keyspace with the semantics and
+// response codes of UniversumDB. Expectations assert that commands are
+// called and optionally replace their answers:
+//
+//	mock := universummock.New()
+//	mock.ExpectGet("user:1").Return(&universum.GetResult{Value: "alice", Code: universum.RespRecordFound})
+//
+//	service := NewService(mock) // accepts a universum.Cmdable
+//	...
+//
+//	mock.AssertExpectations(t)
+package universummock
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cshekharsharma/resp-go/resp3"
+	"github.com/cshekharsharma/universum-client-go"
+	"github.com/cshekharsharma/universum-client-go/internal/memstore"
+	"github.com/cshekharsharma/universum-client-go/universumtest"
+)
+
+const (
+	commandPing     string = "PING"
+	commandExists   string = "EXISTS"
+	commandGet      string = "GET"
+	commandSet      string = "SET"
+	commandDelete   string = "DELETE"
+	commandIncr     string = "INCR"
+	commandDecr     string = "DECR"
+	commandAppend   string = "APPEND"
+	commandMget     string = "MGET"
+	commandMset     string = "MSET"
+	commandMdelete  string = "MDELETE"
+	commandTtl      string = "TTL"
+	commandExpire   string = "EXPIRE"
+	commandSnapshot string = "SNAPSHOT"
+	commandInfo     string = "INFO"
+	commandHelp     string = "HELP"
+)
+
+// Option configures a Mock.
+type Option func(*Mock)
+
+// WithClock makes the mock expire keys according to the given clock instead
+// of the system time, e.g. a universumtest.ManualClock.
+func WithClock(clock universumtest.Clock) Option {
+	return func(m *Mock) {
+		m.clock = clock
+	}
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Mock is an in-memory fake of the Universum client. It is safe for
+// concurrent use.
+type Mock struct {
+	clock universumtest.Clock
+	store *memstore.Store
+
+	mu           sync.Mutex
+	expectations []*expectation
+}
+
+var _ universum.Cmdable = (*Mock)(nil)
+
+// New returns a mock with an empty keyspace and no expectations.
+func New(options ...Option) *Mock {
+	m := &Mock{clock: systemClock{}}
+
+	for _, option := range options {
+		option(m)
+	}
+
+	m.store = memstore.New(m.clock)
+	return m
+}
+
+// checkWriteable rejects the values the client refuses to send, before any
+// expectation is matched
+func checkWriteable(value interface{}) error {
+	if !universum.IsWriteableDatatype(value) {
+		return fmt.Errorf("provided datatype is not supported for write operations, "+
+			"only int|float|bool|string|[]interface{} types are supported: %w", universum.ErrInvalidDatatype)
+	}
+	return nil
+}
+
+// normalize converts a value to the form it would have after a round trip
+// to the server, e.g. int to int64
+func normalize(value interface{}) (interface{}, error) {
+	encoded, err := resp3.Encode(value)
+	if err != nil {
+		return nil, fmt.Errorf("provided datatype is not supported for write operations: %w", universum.ErrInvalidDatatype)
+	}
+
+	return resp3.Decode(bufio.NewReaderSize(strings.NewReader(encoded), len(encoded)))
+}
+
+// Get retrieves the value of a key.
+func (m *Mock) Get(ctx context.Context, key string) (*universum.GetResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if e := m.match(commandGet, key); e != nil {
+		return respond[*universum.GetResult](e)
+	}
+
+	if value, found := m.store.Get(key); found {
+		return &universum.GetResult{Value: value, Code: universum.RespRecordFound}, nil
+	}
+
+	return &universum.GetResult{Code: universum.RespRecordNotFound}, nil
+}
+
+// Set sets the value of a key with an optional TTL in seconds.
+func (m *Mock) Set(ctx context.Context, key string, value interface{}, ttl int64) (*universum.SetResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := checkWriteable(value); err != nil {
+		return nil, err
+	}
+
+	if e := m.match(commandSet, key, value, ttl); e != nil {
+		return respond[*universum.SetResult](e)
+	}
+
+	normalized, err := normalize(value)
+	if err != nil {
+		return nil, err
+	}
+
+	m.store.Set(key, normalized, ttl)
+	return &universum.SetResult{Success: true, Code: universum.RespRecordUpdated}, nil
+}
+
+// Exists checks if a key exists.
+func (m *Mock) Exists(ctx context.Context, key string) (*universum.ExistsResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if e := m.match(commandExists, key); e != nil {
+		return respond[*universum.ExistsResult](e)
+	}
+
+	if _, found := m.store.Get(key); found {
+		return &universum.ExistsResult{Found: true, Code: universum.RespRecordFound}, nil
+	}
+
+	return &universum.ExistsResult{Found: false, Code: universum.RespRecordNotFound}, nil
+}
+
+// Delete deletes a key.
+func (m *Mock) Delete(ctx context.Context, key string) (*universum.DeleteResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if e := m.match(commandDelete, key); e != nil {
+		return respond[*universum.DeleteResult](e)
+	}
+
+	if m.store.Delete(key) {
+		return &universum.DeleteResult{Deleted: true, Code: universum.RespRecordDeleted}, nil
+	}
+
+	return &universum.DeleteResult{Deleted: false, Code: universum.RespRecordNotFound}, nil
+}
+
+// Increment increases the integer value of a key by offset.
+func (m *Mock) Increment(ctx context.Context, key string, offset int64) (*universum.IncrementResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if e := m.match(commandIncr, key, offset); e != nil {
+		return respond[*universum.IncrementResult](e)
+	}
+
+	value, ok := m.store.Increment(key, offset)
+	if !ok {
+		return &universum.IncrementResult{Code: universum.RespIncrInvalidType}, nil
+	}
+
+	return &universum.IncrementResult{NewValue: value, Code: universum.RespRecordUpdated}, nil
+}
+
+// Decrement decreases the integer value of a key by offset.
+func (m *Mock) Decrement(ctx context.Context, key string, offset int64) (*universum.DecrementResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if e := m.match(commandDecr, key, offset); e != nil {
+		return respond[*universum.DecrementResult](e)
+	}
+
+	value, ok := m.store.Increment(key, -offset)
+	if !ok {
+		return &universum.DecrementResult{Code: universum.RespIncrInvalidType}, nil
+	}
+
+	return &universum.DecrementResult{NewValue: value, Code: universum.RespRecordUpdated}, nil
+}
+
+// Append appends a string to the string value of a key.
+func (m *Mock) Append(ctx context.Context, key string, value string) (*universum.AppendResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if e := m.match(commandAppend, key, value); e != nil {
+		return respond[*universum.AppendResult](e)
+	}
+
+	length, code := m.store.Append(key, value)
+	return &universum.AppendResult{ContentLength: length, Code: code}, nil
+}
+
+// MGet retrieves the values of multiple keys.
+func (m *Mock) MGet(ctx context.Context, keys []string) (*universum.MGetResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if e := m.match(commandMget, keys); e != nil {
+		return respond[*universum.MGetResult](e)
+	}
+
+	if len(keys) < 1 {
+		return nil, fmt.Errorf("MGET requires at least one key: %w", universum.ErrInvalidRequest)
+	}
+
+	values := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		if value, found := m.store.Get(key); found {
+			values[key] = map[string]interface{}{"Code": universum.RespRecordFound, "Value": value}
+		} else {
+			values[key] = map[string]interface{}{"Code": universum.RespRecordNotFound, "Value": nil}
+		}
+	}
+
+	return &universum.MGetResult{Values: values, Code: universum.RespMgetCompleted}, nil
+}
+
+// MSet sets multiple key-value pairs.
+func (m *Mock) MSet(ctx context.Context, kv map[string]interface{}) (*universum.MSetResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for key, value := range kv {
+		if err := checkWriteable(value); err != nil {
+			return nil, fmt.Errorf("value of key %s: %w", key, err)
+		}
+	}
+
+	if e := m.match(commandMset, kv); e != nil {
+		return respond[*universum.MSetResult](e)
+	}
+
+	if len(kv) < 1 {
+		return nil, fmt.Errorf("MSET requires at least one key: %w", universum.ErrInvalidRequest)
+	}
+
+	normalized := make(map[string]interface{}, len(kv))
+	for key, value := range kv {
+		v, err := normalize(value)
+		if err != nil {
+			return nil, err
+		}
+		normalized[key] = v
+	}
+
+	successes := make(map[string]bool, len(kv))
+	for key, value := range normalized {
+		m.store.Set(key, value, 0)
+		successes[key] = true
+	}
+
+	return &universum.MSetResult{Successes: successes, Code: universum.RespMsetCompleted}, nil
+}
+
+// MDelete deletes multiple keys.
+func (m *Mock) MDelete(ctx context.Context, keys []string) (*universum.MDeleteResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if e := m.match(commandMdelete, keys); e != nil {
+		return respond[*universum.MDeleteResult](e)
+	}
+
+	if len(keys) < 1 {
+		return nil, fmt.Errorf("MDELETE requires at least one key: %w", universum.ErrInvalidRequest)
+	}
+
+	deletions := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		m.store.Delete(key)
+		deletions[key] = true
+	}
+
+	return &universum.MDeleteResult{Deletions: deletions, Code: universum.RespMdelCompleted}, nil
+}
+
+// TTL retrieves the remaining time-to-live of a key, -1s for keys without expiry.
+func (m *Mock) TTL(ctx context.Context, key string) (*universum.TTLResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if e := m.match(commandTtl, key); e != nil {
+		return respond[*universum.TTLResult](e)
+	}
+
+	ttl, found := m.store.TTL(key)
+	if !found {
+		return &universum.TTLResult{Code: universum.RespRecordNotFound}, nil
+	}
+
+	return &universum.TTLResult{TTL: time.Duration(ttl) * time.Second, Code: universum.RespRecordFound}, nil
+}
+
+// Expire sets the time-to-live of a key in seconds.
+func (m *Mock) Expire(ctx context.Context, key string, ttl int64) (*universum.ExpireResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if e := m.match(commandExpire, key, ttl); e != nil {
+		return respond[*universum.ExpireResult](e)
+	}
+
+	if m.store.Expire(key, ttl) {
+		return &universum.ExpireResult{Success: true, Code: universum.RespRecordUpdated}, nil
+	}
+
+	return &universum.ExpireResult{Success: false, Code: universum.RespRecordNotFound}, nil
+}
+
+// Ping checks that the mock is responsive.
+func (m *Mock) Ping(ctx context.Context) (*universum.PingResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if e := m.match(commandPing); e != nil {
+		return respond[*universum.PingResult](e)
+	}
+
+	return &universum.PingResult{Message: "OK", Code: universum.RespPingSuccess}, nil
+}
+
+// Info retrieves information about the keyspace.
+func (m *Mock) Info(ctx context.Context) (*universum.InfoResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if e := m.match(commandInfo); e != nil {
+		return respond[*universum.InfoResult](e)
+	}
+
+	raw := fmt.Sprintf("# Server\nversion:universummock\n\n# Keyspace\nkeys:%d\n", m.store.Len())
+	return &universum.InfoResult{Raw: raw, Code: universum.RespInfoContentOk}, nil
+}
+
+// Help lists the commands supported by the mock.
+func (m *Mock) Help(ctx context.Context) (*universum.HelpResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if e := m.match(commandHelp); e != nil {
+		return respond[*universum.HelpResult](e)
+	}
+
+	return &universum.HelpResult{Raw: "Supported commands:\n" + strings.Join(universum.Commands(), "\n") + "\n",
+		Code: universum.RespHelpContentOk}, nil
+}
+
+// Snapshot pretends to start a snapshot of the keyspace.
+func (m *Mock) Snapshot(ctx context.Context) (*universum.SnapshotResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if e := m.match(commandSnapshot); e != nil {
+		return respond[*universum.SnapshotResult](e)
+	}
+
+	return &universum.SnapshotResult{Started: true, Code: universum.RespSnapshotStarted}, nil
+}
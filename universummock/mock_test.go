@@ -0,0 +1,151 @@
+package universummock_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cshekharsharma/universum-client-go"
+	"github.com/cshekharsharma/universum-client-go/universummock"
+	"github.com/cshekharsharma/universum-client-go/universumtest"
+)
+
+func TestMock_Fake(t *testing.T) {
+	clock := universumtest.NewManualClock(time.Unix(1700000000, 0))
+	var kv universum.Cmdable = universummock.New(universummock.WithClock(clock))
+	ctx := context.Background()
+
+	if _, err := kv.Set(ctx, "counter", 10, 5); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	incr, err := kv.Increment(ctx, "counter", 5)
+	if err != nil || incr.NewValue != 15 || incr.Code != universum.RespRecordUpdated {
+		t.Fatalf("Expected counter to be incremented to 15, got %+v, %v", incr, err)
+	}
+
+	if _, err := kv.MSet(ctx, map[string]interface{}{"list": []interface{}{true, 0}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mget, err := kv.MGet(ctx, []string{"list", "missing"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"list":    map[string]interface{}{"Code": universum.RespRecordFound, "Value": []interface{}{true, int64(0)}},
+		"missing": map[string]interface{}{"Code": universum.RespRecordNotFound, "Value": nil},
+	}
+	if !reflect.DeepEqual(mget.Values, expected) {
+		t.Fatalf("Expected values %#v, got %#v", expected, mget.Values)
+	}
+
+	large := strings.Repeat("x", 64*1024)
+	if _, err := kv.Set(ctx, "large", large, 0); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if get, err := kv.Get(ctx, "large"); err != nil || get.Value != large {
+		t.Fatalf("Expected the large value to be stored whole, got %v", err)
+	}
+
+	clock.Advance(5 * time.Second)
+
+	get, err := kv.Get(ctx, "counter")
+	if err != nil || get.Code != universum.RespRecordNotFound {
+		t.Fatalf("Expected the counter to have expired, got %+v, %v", get, err)
+	}
+
+	if _, err := kv.MGet(ctx, nil); !errors.Is(err, universum.ErrInvalidRequest) {
+		t.Fatalf("Expected ErrInvalidRequest, got %v", err)
+	}
+}
+
+func TestMock_Expectations(t *testing.T) {
+	mock := universummock.New()
+	ctx := context.Background()
+
+	mock.ExpectGet("user:1").Return(&universum.GetResult{Value: "alice", Code: universum.RespRecordFound})
+	mock.ExpectSet("user:2", "bob", 0)
+	mock.ExpectPing().ReturnError(universum.ErrCircuitOpen)
+
+	get, err := mock.Get(ctx, "user:1")
+	if err != nil || get.Value != "alice" {
+		t.Fatalf("Expected the canned result, got %+v, %v", get, err)
+	}
+
+	if _, err := mock.Set(ctx, "user:2", "bob", 0); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// expectations without a result are answered by the fake
+	get, err = mock.Get(ctx, "user:2")
+	if err != nil || get.Value != "bob" {
+		t.Fatalf("Expected the stored value, got %+v, %v", get, err)
+	}
+
+	if _, err := mock.Ping(ctx); !errors.Is(err, universum.ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen, got %v", err)
+	}
+
+	// every expectation matches a single call
+	get, err = mock.Get(ctx, "user:1")
+	if err != nil || get.Code != universum.RespRecordNotFound {
+		t.Fatalf("Expected the fake to answer, got %+v, %v", get, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Expected all expectations to be met, got %v", err)
+	}
+}
+
+func TestMock_RejectsUnwriteableValues(t *testing.T) {
+	client, err := universum.NewClient(universumtest.NewServer(t).Options())
+	if err != nil {
+		t.Fatalf("Expected no error while creating client, got %v", err)
+	}
+	defer client.Close()
+
+	mock := universummock.New()
+	mock.ExpectSet("key", struct{}{}, 0).Return(&universum.SetResult{Success: true})
+
+	ctx := context.Background()
+	for name, kv := range map[string]universum.Cmdable{"client": client, "mock": mock} {
+		if _, err := kv.Set(ctx, "key", struct{}{}, 0); !errors.Is(err, universum.ErrInvalidDatatype) {
+			t.Fatalf("Expected %s to reject a struct value, got %v", name, err)
+		}
+
+		mset := map[string]interface{}{"ok": 1, "nested": map[string]interface{}{"a": 1}}
+		if _, err := kv.MSet(ctx, mset); !errors.Is(err, universum.ErrInvalidDatatype) {
+			t.Fatalf("Expected %s to reject a map value, got %v", name, err)
+		}
+	}
+}
+
+func TestMock_UnmetExpectations(t *testing.T) {
+	mock := universummock.New()
+
+	mock.ExpectDelete("session:1")
+	mock.ExpectMGet([]string{"a", "b"})
+	mock.ExpectMGet([]string{"a", "b"})
+
+	if _, err := mock.MGet(context.Background(), []string{"a", "b"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	err := mock.ExpectationsWereMet()
+	if !errors.Is(err, universummock.ErrUnmetExpectations) {
+		t.Fatalf("Expected ErrUnmetExpectations, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "DELETE session:1") || !strings.Contains(err.Error(), "MGET [a b]") {
+		t.Fatalf("Expected the unmet commands to be listed, got %v", err)
+	}
+
+	mock.ClearExpectations()
+	mock.AssertExpectations(t)
+}
@@ -0,0 +1,388 @@
+package universumtest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cshekharsharma/universum-client-go"
+)
+
+// ServerVersion is the version announced by the server in the HELLO handshake.
+const ServerVersion = "universumtest"
+
+const (
+	commandPing     string = "PING"
+	commandExists   string = "EXISTS"
+	commandGet      string = "GET"
+	commandSet      string = "SET"
+	commandDelete   string = "DELETE"
+	commandIncr     string = "INCR"
+	commandDecr     string = "DECR"
+	commandAppend   string = "APPEND"
+	commandMget     string = "MGET"
+	commandMset     string = "MSET"
+	commandMdelete  string = "MDELETE"
+	commandTtl      string = "TTL"
+	commandExpire   string = "EXPIRE"
+	commandSnapshot string = "SNAPSHOT"
+	commandInfo     string = "INFO"
+	commandHelp     string = "HELP"
+	commandAuth     string = "AUTH"
+	commandHello    string = "HELLO"
+)
+
+// commandUsage lists the supported commands and their arguments, in the order
+// they are advertised by HELLO and HELP
+var commandUsage = []struct {
+	name  string
+	usage string
+}{
+	{commandPing, ""},
+	{commandExists, "key"},
+	{commandGet, "key"},
+	{commandSet, "key value ttl"},
+	{commandDelete, "key"},
+	{commandIncr, "key offset"},
+	{commandDecr, "key offset"},
+	{commandAppend, "key value"},
+	{commandMget, "[key ...]"},
+	{commandMset, "{key: value ...}"},
+	{commandMdelete, "[key ...]"},
+	{commandTtl, "key"},
+	{commandExpire, "key ttl"},
+	{commandSnapshot, ""},
+	{commandInfo, ""},
+	{commandHelp, ""},
+	{commandAuth, "token | username password"},
+	{commandHello, "protocol clientname clientid version"},
+}
+
+// session holds the state of a single client connection
+type session struct {
+	authenticated bool
+	clientName    string
+}
+
+// reply builds the [value, code, message] reply triplet.
+func reply(value interface{}, code int64, message string) []interface{} {
+	return []interface{}{value, code, message}
+}
+
+func invalidInput(command string) []interface{} {
+	return reply(nil, universum.RespInvalidCmdInput, "invalid input for command "+command)
+}
+
+// execute runs a decoded command, its name followed by the arguments, and
+// returns the reply triplet
+func (s *Server) execute(sess *session, cmd []interface{}) []interface{} {
+	name, ok := cmd[0].(string)
+	if !ok {
+		return reply(nil, universum.RespInvalidCmdInput, "invalid command name")
+	}
+
+	name = strings.ToUpper(name)
+	args := cmd[1:]
+
+	if !sess.authenticated && name != commandAuth && name != commandHello {
+		return reply(nil, universum.RespAuthRequired, "authentication required")
+	}
+
+	switch name {
+	case commandPing:
+		return reply("OK", universum.RespPingSuccess, "")
+
+	case commandHello:
+		return s.hello(sess, args)
+
+	case commandAuth:
+		return s.auth(sess, args)
+
+	case commandExists:
+		key, ok := keyArg(args, 1)
+		if !ok {
+			return invalidInput(name)
+		}
+
+		if _, found := s.store.Get(key); found {
+			return reply(true, universum.RespRecordFound, "")
+		}
+		return reply(false, universum.RespRecordNotFound, "")
+
+	case commandGet:
+		key, ok := keyArg(args, 1)
+		if !ok {
+			return invalidInput(name)
+		}
+
+		if value, found := s.store.Get(key); found {
+			return reply(map[string]interface{}{"Value": value}, universum.RespRecordFound, "")
+		}
+		return reply(nil, universum.RespRecordNotFound, "")
+
+	case commandSet:
+		if len(args) != 2 && len(args) != 3 {
+			return invalidInput(name)
+		}
+
+		key, ok := args[0].(string)
+		if !ok || args[1] == nil {
+			return invalidInput(name)
+		}
+
+		var ttl int64
+		if len(args) == 3 {
+			if ttl, ok = args[2].(int64); !ok {
+				return invalidInput(name)
+			}
+		}
+
+		s.store.Set(key, args[1], ttl)
+		return reply(true, universum.RespRecordUpdated, "")
+
+	case commandDelete:
+		key, ok := keyArg(args, 1)
+		if !ok {
+			return invalidInput(name)
+		}
+
+		if s.store.Delete(key) {
+			return reply(true, universum.RespRecordDeleted, "")
+		}
+		return reply(false, universum.RespRecordNotFound, "")
+
+	case commandIncr, commandDecr:
+		key, ok := keyArg(args, 2)
+		if !ok {
+			return invalidInput(name)
+		}
+
+		offset, ok := args[1].(int64)
+		if !ok {
+			return invalidInput(name)
+		}
+
+		if name == commandDecr {
+			offset = -offset
+		}
+
+		value, ok := s.store.Increment(key, offset)
+		if !ok {
+			return reply(int64(0), universum.RespIncrInvalidType, "value is not an integer")
+		}
+		return reply(value, universum.RespRecordUpdated, "")
+
+	case commandAppend:
+		key, ok := keyArg(args, 2)
+		if !ok {
+			return invalidInput(name)
+		}
+
+		suffix, ok := args[1].(string)
+		if !ok {
+			return invalidInput(name)
+		}
+
+		length, code := s.store.Append(key, suffix)
+		return reply(length, code, "")
+
+	case commandMget:
+		keys, ok := keysArg(args)
+		if !ok {
+			return invalidInput(name)
+		}
+
+		values := make(map[string]interface{}, len(keys))
+		for _, key := range keys {
+			if value, found := s.store.Get(key); found {
+				values[key] = map[string]interface{}{"Code": universum.RespRecordFound, "Value": value}
+			} else {
+				values[key] = map[string]interface{}{"Code": universum.RespRecordNotFound, "Value": nil}
+			}
+		}
+		return reply(values, universum.RespMgetCompleted, "")
+
+	case commandMset:
+		if len(args) != 1 {
+			return invalidInput(name)
+		}
+
+		kv, ok := args[0].(map[string]interface{})
+		if !ok {
+			return invalidInput(name)
+		}
+
+		results := make(map[string]interface{}, len(kv))
+		for key, value := range kv {
+			s.store.Set(key, value, 0)
+			results[key] = true
+		}
+		return reply(results, universum.RespMsetCompleted, "")
+
+	case commandMdelete:
+		keys, ok := keysArg(args)
+		if !ok {
+			return invalidInput(name)
+		}
+
+		results := make(map[string]interface{}, len(keys))
+		for _, key := range keys {
+			s.store.Delete(key)
+			results[key] = true
+		}
+		return reply(results, universum.RespMdelCompleted, "")
+
+	case commandTtl:
+		key, ok := keyArg(args, 1)
+		if !ok {
+			return invalidInput(name)
+		}
+
+		if ttl, found := s.store.TTL(key); found {
+			return reply(ttl, universum.RespRecordFound, "")
+		}
+		return reply(int64(0), universum.RespRecordNotFound, "")
+
+	case commandExpire:
+		key, ok := keyArg(args, 2)
+		if !ok {
+			return invalidInput(name)
+		}
+
+		ttl, ok := args[1].(int64)
+		if !ok {
+			return invalidInput(name)
+		}
+
+		if s.store.Expire(key, ttl) {
+			return reply(true, universum.RespRecordUpdated, "")
+		}
+		return reply(false, universum.RespRecordNotFound, "")
+
+	case commandSnapshot:
+		return reply(true, universum.RespSnapshotStarted, "")
+
+	case commandInfo:
+		return reply(s.info(), universum.RespInfoContentOk, "")
+
+	case commandHelp:
+		return reply(help(), universum.RespHelpContentOk, "")
+	}
+
+	return reply(nil, universum.RespInvalidCmdInput, "unknown command "+name)
+}
+
+// hello answers the handshake with the server version, the agreed protocol
+// version and the supported commands as capabilities
+func (s *Server) hello(sess *session, args []interface{}) []interface{} {
+	if s.noHello {
+		return reply(nil, universum.RespInvalidCmdInput, "unknown command "+commandHello)
+	}
+
+	protocol := universum.ProtocolVersion
+	if len(args) > 0 {
+		requested, ok := args[0].(int64)
+		if !ok {
+			return invalidInput(commandHello)
+		}
+
+		if requested < protocol {
+			protocol = requested
+		}
+	}
+
+	if len(args) > 1 {
+		sess.clientName, _ = args[1].(string)
+	}
+
+	capabilities := make([]interface{}, 0, len(commandUsage))
+	for _, command := range commandUsage {
+		capabilities = append(capabilities, command.name)
+	}
+
+	return reply(map[string]interface{}{
+		"version":      ServerVersion,
+		"protocol":     protocol,
+		"capabilities": capabilities,
+	}, universum.RespHelloSuccess, "")
+}
+
+// auth checks a token, or a username and password, against the configured
+// credentials. Servers without credentials accept any AUTH.
+func (s *Server) auth(sess *session, args []interface{}) []interface{} {
+	if !s.requiresAuth() {
+		return reply(true, universum.RespAuthSuccess, "")
+	}
+
+	valid := false
+	switch len(args) {
+	case 1:
+		token, _ := args[0].(string)
+		valid = s.token != "" && token == s.token
+
+	case 2:
+		username, _ := args[0].(string)
+		password, _ := args[1].(string)
+		valid = s.token == "" && username == s.username && password == s.password
+
+	default:
+		return invalidInput(commandAuth)
+	}
+
+	if !valid {
+		return reply(false, universum.RespAuthFailed, "invalid credentials")
+	}
+
+	sess.authenticated = true
+	return reply(true, universum.RespAuthSuccess, "")
+}
+
+func (s *Server) info() string {
+	return fmt.Sprintf("# Server\nversion:%s\nprotocol:%d\n\n# Keyspace\nkeys:%d\n",
+		ServerVersion, universum.ProtocolVersion, s.store.Len())
+}
+
+func help() string {
+	var b strings.Builder
+
+	b.WriteString("Supported commands:\n")
+	for _, command := range commandUsage {
+		b.WriteString(strings.TrimSpace(command.name + " " + command.usage))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// keyArg returns the key of a command expecting exactly count arguments, the
+// first one being the key
+func keyArg(args []interface{}, count int) (string, bool) {
+	if len(args) != count {
+		return "", false
+	}
+
+	key, ok := args[0].(string)
+	return key, ok
+}
+
+// keysArg returns the keys of a command expecting a single list of keys
+func keysArg(args []interface{}) ([]string, bool) {
+	if len(args) != 1 {
+		return nil, false
+	}
+
+	list, ok := args[0].([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, false
+	}
+
+	keys := make([]string, 0, len(list))
+	for _, item := range list {
+		key, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, true
+}
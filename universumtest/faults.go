@@ -0,0 +1,159 @@
+package universumtest
+
+import (
+	"strings"
+	"time"
+
+	"github.com/cshekharsharma/universum-client-go"
+)
+
+// AnyCommand injects faults into every command except the connection setup
+// commands HELLO and AUTH, which can still be targeted by name.
+const AnyCommand = "*"
+
+// Fault describes how the server misbehaves while answering a command. The
+// zero Fault answers normally.
+type Fault struct {
+	// Latency delays the reply.
+	Latency time.Duration
+
+	// Code replies with the response code, e.g. RespServerBusy or
+	// RespServerShuttingDown, instead of running the command.
+	Code int64
+
+	// Drop closes the connection instead of replying.
+	Drop bool
+
+	// DropAfter closes the connection after writing only this many bytes
+	// of the reply.
+	DropAfter int
+
+	// ChunkSize writes the reply in partial writes of this many bytes,
+	// ChunkDelay apart.
+	ChunkSize  int
+	ChunkDelay time.Duration
+
+	// Garbage is written instead of the encoded reply.
+	Garbage []byte
+
+	// NoDelimiter leaves out the trailer of the reply.
+	NoDelimiter bool
+
+	// Times limits the fault to this many commands, after which the next
+	// queued fault applies. Zero keeps the fault until ClearFaults.
+	Times int
+}
+
+// injectedFault is a queued fault with the number of commands it still applies to
+type injectedFault struct {
+	fault     Fault
+	remaining int
+}
+
+// Inject queues faults for the command, or for every command with AnyCommand.
+// Faults apply in the order they were queued, each to the number of commands
+// given by its Times; faults queued for a command take precedence over
+// AnyCommand.
+func (s *Server) Inject(command string, faults ...Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	command = strings.ToUpper(command)
+	for _, fault := range faults {
+		s.faults[command] = append(s.faults[command], &injectedFault{fault: fault, remaining: fault.Times})
+	}
+}
+
+// ClearFaults removes all queued faults and the accept delay.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = make(map[string][]*injectedFault)
+	s.acceptDelay = 0
+}
+
+// SetAcceptDelay makes the server wait for the given duration before serving
+// every newly accepted connection.
+func (s *Server) SetAcceptDelay(delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.acceptDelay = delay
+}
+
+// DropConnections closes all client connections while the server keeps
+// accepting new ones.
+func (s *Server) DropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for conn := range s.conns {
+		conn.Close()
+	}
+}
+
+// nextFault returns the fault to apply to the command, if any
+func (s *Server) nextFault(command string) (Fault, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range []string{command, AnyCommand} {
+		if key == AnyCommand && (command == commandHello || command == commandAuth) {
+			continue
+		}
+
+		queue := s.faults[key]
+		if len(queue) == 0 {
+			continue
+		}
+
+		injected := queue[0]
+		if injected.fault.Times > 0 {
+			injected.remaining--
+			if injected.remaining == 0 {
+				s.faults[key] = queue[1:]
+			}
+		}
+
+		return injected.fault, true
+	}
+
+	return Fault{}, false
+}
+
+func (s *Server) getAcceptDelay() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.acceptDelay
+}
+
+// faultReply builds the reply for a fault replacing the response code
+func faultReply(code int64) []interface{} {
+	switch code {
+	case universum.RespServerBusy:
+		return reply(nil, code, "server is busy")
+	case universum.RespServerShuttingDown:
+		return reply(nil, code, "server is shutting down")
+	}
+
+	return reply(nil, code, "injected fault")
+}
+
+// sleep waits for the duration and reports false if the server was closed meanwhile
+func (s *Server) sleep(d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-s.done:
+		return false
+	}
+}
@@ -0,0 +1,333 @@
+// Package universumtest provides an in-process Universum server for tests.
+//
+// The server listens on a random local port, speaks RESP3 with the same reply
+// trailer as UniversumDB and implements every command of the client with the
+// same response codes, keeping the data in memory:
+//
+//	func TestSomething(t *testing.T) {
+//		srv := universumtest.NewServer(t)
+//
+//		client, err := universum.NewClient(srv.Options())
+//		...
+//	}
+package universumtest
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cshekharsharma/resp-go/resp3"
+	"github.com/cshekharsharma/universum-client-go"
+	"github.com/cshekharsharma/universum-client-go/internal/memstore"
+)
+
+// replyDelimiter terminates every reply sent by the server
+const replyDelimiter = "\x04\x04\x04\x04"
+
+// maxRequestSize is the size of the largest request the server accepts
+const maxRequestSize = 1 << 26
+
+// errRequestTooLarge closes connections sending requests over maxRequestSize
+var errRequestTooLarge = errors.New("request too large")
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithClock makes the server expire keys according to the given clock
+// instead of the system time, e.g. a ManualClock.
+func WithClock(clock Clock) Option {
+	return func(s *Server) {
+		s.clock = clock
+	}
+}
+
+// WithCredentials requires every connection to authenticate with the given
+// username and password before sending commands.
+func WithCredentials(username, password string) Option {
+	return func(s *Server) {
+		s.username = username
+		s.password = password
+	}
+}
+
+// WithToken requires every connection to authenticate with the given token
+// before sending commands.
+func WithToken(token string) Option {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithoutHello makes the server reject the HELLO handshake like servers which
+// predate it.
+func WithoutHello() Option {
+	return func(s *Server) {
+		s.noHello = true
+	}
+}
+
+// Server is an in-memory Universum server listening on a local TCP port.
+type Server struct {
+	listener net.Listener
+	clock    Clock
+	store    *memstore.Store
+
+	username string
+	password string
+	token    string
+	noHello  bool
+
+	mu          sync.Mutex
+	conns       map[net.Conn]struct{}
+	faults      map[string][]*injectedFault
+	acceptDelay time.Duration
+	closed      bool
+	done        chan struct{}
+	wg          sync.WaitGroup
+}
+
+// NewServer starts a server on a random local port. The server is closed
+// when the test and all its subtests complete.
+func NewServer(t testing.TB, options ...Option) *Server {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("universumtest: failed to listen on a local port: %v", err)
+	}
+
+	s := &Server{
+		listener: listener,
+		clock:    systemClock{},
+		conns:    make(map[net.Conn]struct{}),
+		faults:   make(map[string][]*injectedFault),
+		done:     make(chan struct{}),
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	s.store = memstore.New(s.clock)
+
+	s.wg.Add(1)
+	go s.serve()
+
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Addr returns the address the server listens on, in host:port form.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Options returns client options pointing to the server, including the
+// credentials the server requires. Every call returns a new copy which
+// the caller may modify.
+func (s *Server) Options() *universum.Options {
+	return &universum.Options{
+		HostAddr: s.Addr(),
+		Username: s.username,
+		Password: s.password,
+		Token:    s.token,
+	}
+}
+
+// Close stops the server and closes all client connections.
+func (s *Server) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+
+	s.closed = true
+	close(s.done)
+	s.listener.Close()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		if !s.sleep(s.getAcceptDelay()) {
+			s.wg.Done()
+			return
+		}
+
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	session := &session{authenticated: !s.requiresAuth()}
+	reader := &requestReader{conn: conn, chunk: make([]byte, 32*1024)}
+
+	for {
+		decoded, err := reader.next()
+		if err != nil {
+			return
+		}
+
+		cmd, ok := decoded.([]interface{})
+		if !ok || len(cmd) == 0 {
+			return
+		}
+
+		if !s.respond(conn, session, cmd) {
+			return
+		}
+	}
+}
+
+// requestReader reads the requests of a connection. The decoder reads bulk
+// strings with a single Read, which returns only part of a value received in
+// several segments, so requests are buffered until they are complete.
+type requestReader struct {
+	conn    net.Conn
+	pending []byte
+	chunk   []byte
+}
+
+// next returns the next request of the connection
+func (r *requestReader) next() (interface{}, error) {
+	for {
+		if decoded, size, ok := decodeRequest(r.pending); ok {
+			r.pending = r.pending[size:]
+			return decoded, nil
+		}
+
+		if len(r.pending) > maxRequestSize {
+			return nil, errRequestTooLarge
+		}
+
+		n, err := r.conn.Read(r.chunk)
+		if err != nil {
+			return nil, err
+		}
+		r.pending = append(r.pending, r.chunk[:n]...)
+	}
+}
+
+// decodeRequest decodes the request at the start of data and returns its
+// size, reporting false if data does not hold a complete request
+func decodeRequest(data []byte) (decoded interface{}, size int, ok bool) {
+	if len(data) == 0 {
+		return nil, 0, false
+	}
+
+	defer func() {
+		if recover() != nil {
+			decoded, size, ok = nil, 0, false
+		}
+	}()
+
+	reader := bufio.NewReaderSize(bytes.NewReader(data), len(data))
+	decoded, err := resp3.Decode(reader)
+	if err != nil {
+		return nil, 0, false
+	}
+
+	return decoded, len(data) - reader.Buffered(), true
+}
+
+// respond runs the command and writes the reply, applying the fault injected
+// for the command. It reports false if the connection must be closed.
+func (s *Server) respond(conn net.Conn, sess *session, cmd []interface{}) bool {
+	name, _ := cmd[0].(string)
+	fault, _ := s.nextFault(strings.ToUpper(name))
+
+	if !s.sleep(fault.Latency) || fault.Drop {
+		return false
+	}
+
+	var payload []byte
+	switch {
+	case fault.Garbage != nil:
+		payload = append([]byte(nil), fault.Garbage...)
+
+	case fault.Code != 0:
+		encoded, err := resp3.Encode(faultReply(fault.Code))
+		if err != nil {
+			return false
+		}
+		payload = []byte(encoded)
+
+	default:
+		encoded, err := resp3.Encode(s.execute(sess, cmd))
+		if err != nil {
+			return false
+		}
+		payload = []byte(encoded)
+	}
+
+	if !fault.NoDelimiter {
+		payload = append(payload, replyDelimiter...)
+	}
+
+	if fault.DropAfter > 0 {
+		if fault.DropAfter < len(payload) {
+			payload = payload[:fault.DropAfter]
+		}
+		_, _ = conn.Write(payload)
+		return false
+	}
+
+	chunkSize := fault.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = len(payload)
+	}
+
+	for start := 0; start < len(payload); start += chunkSize {
+		if start > 0 && !s.sleep(fault.ChunkDelay) {
+			return false
+		}
+
+		end := min(start+chunkSize, len(payload))
+		if _, err := conn.Write(payload[start:end]); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *Server) requiresAuth() bool {
+	return s.username != "" || s.password != "" || s.token != ""
+}
@@ -0,0 +1,220 @@
+package universumtest_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cshekharsharma/universum-client-go"
+	"github.com/cshekharsharma/universum-client-go/universumtest"
+)
+
+func newClient(t *testing.T, opts *universum.Options) *universum.Client {
+	t.Helper()
+
+	client, err := universum.NewClient(opts)
+	if err != nil {
+		t.Fatalf("Expected no error while creating client, got %v", err)
+	}
+
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestServer_LargeValues(t *testing.T) {
+	client := newClient(t, universumtest.NewServer(t).Options())
+	ctx := context.Background()
+	value := strings.Repeat("x", 64*1024)
+
+	if _, err := client.Set(ctx, "large", value, 0); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	result, err := client.Append(ctx, "large", "y")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.ContentLength != int64(len(value)+1) {
+		t.Fatalf("Expected a value of %d bytes, got %d", len(value)+1, result.ContentLength)
+	}
+}
+
+func TestServer_Expiry(t *testing.T) {
+	clock := universumtest.NewManualClock(time.Unix(1700000000, 0))
+	client := newClient(t, universumtest.NewServer(t, universumtest.WithClock(clock)).Options())
+	ctx := context.Background()
+
+	if _, err := client.Set(ctx, "session", "abc", 10); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := client.Set(ctx, "forever", "xyz", 0); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	clock.Advance(2500 * time.Millisecond)
+
+	ttl, err := client.TTL(ctx, "session")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if ttl.Code != universum.RespRecordFound || ttl.TTL != 8*time.Second {
+		t.Fatalf("Expected TTL of 8s, got %v with code %d", ttl.TTL, ttl.Code)
+	}
+
+	ttl, err = client.TTL(ctx, "forever")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if ttl.TTL != -1*time.Second {
+		t.Fatalf("Expected TTL of -1s for a key without expiry, got %v", ttl.TTL)
+	}
+
+	clock.Advance(8 * time.Second)
+
+	get, err := client.Get(ctx, "session")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if get.Code != universum.RespRecordNotFound || get.Value != nil {
+		t.Fatalf("Expected the key to have expired, got %v with code %d", get.Value, get.Code)
+	}
+
+	get, err = client.Get(ctx, "forever")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if get.Code != universum.RespRecordFound || get.Value != "xyz" {
+		t.Fatalf("Expected the key without expiry to be found, got %v with code %d", get.Value, get.Code)
+	}
+}
+
+func TestServer_InvalidType(t *testing.T) {
+	client := newClient(t, universumtest.NewServer(t).Options())
+	ctx := context.Background()
+
+	if _, err := client.Set(ctx, "name", "universum", 0); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	incr, err := client.Increment(ctx, "name", 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if incr.Code != universum.RespIncrInvalidType {
+		t.Fatalf("Expected code %d, got %d", universum.RespIncrInvalidType, incr.Code)
+	}
+
+	incr, err = client.Increment(ctx, "counter", 5)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if incr.Code != universum.RespRecordUpdated || incr.NewValue != 5 {
+		t.Fatalf("Expected missing counter to be created with 5, got %d with code %d", incr.NewValue, incr.Code)
+	}
+}
+
+func TestServer_Handshake(t *testing.T) {
+	client := newClient(t, universumtest.NewServer(t).Options())
+	ctx := context.Background()
+
+	if _, err := client.Ping(ctx); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	info := client.ServerInfo()
+	if info == nil || info.Version != universumtest.ServerVersion || info.ProtocolVersion != universum.ProtocolVersion {
+		t.Fatalf("Expected server info of the test server, got %+v", info)
+	}
+
+	if supported, err := client.Supports(ctx, "SNAPSHOT"); err != nil || !supported {
+		t.Fatalf("Expected SNAPSHOT to be supported, got %v, %v", supported, err)
+	}
+
+	snapshot, err := client.Snapshot(ctx)
+	if err != nil || !snapshot.Started {
+		t.Fatalf("Expected snapshot to start, got %+v, %v", snapshot, err)
+	}
+}
+
+func TestServer_WithoutHello(t *testing.T) {
+	client := newClient(t, universumtest.NewServer(t, universumtest.WithoutHello()).Options())
+	ctx := context.Background()
+
+	if _, err := client.Ping(ctx); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if info := client.ServerInfo(); info != nil {
+		t.Fatalf("Expected no server info, got %+v", info)
+	}
+
+	help, err := client.Help(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if help.Code != universum.RespHelpContentOk || !strings.Contains(help.Raw, "EXPIRE key ttl") {
+		t.Fatalf("Expected help content, got %q with code %d", help.Raw, help.Code)
+	}
+
+	if supported, err := client.Supports(ctx, "TTL"); err != nil || !supported {
+		t.Fatalf("Expected TTL to be supported via HELP, got %v, %v", supported, err)
+	}
+}
+
+func TestServer_Credentials(t *testing.T) {
+	server := universumtest.NewServer(t, universumtest.WithCredentials("admin", "secret"))
+
+	client := newClient(t, server.Options())
+	if _, err := client.Set(context.Background(), "key", "value", 0); err != nil {
+		t.Fatalf("Expected authenticated client to succeed, got %v", err)
+	}
+
+	opts := server.Options()
+	opts.Password = "wrong"
+
+	_, err := newClient(t, opts).Ping(context.Background())
+	if !errors.Is(err, universum.ErrAuthFailed) {
+		t.Fatalf("Expected ErrAuthFailed, got %v", err)
+	}
+}
+
+func TestServer_Token(t *testing.T) {
+	server := universumtest.NewServer(t, universumtest.WithToken("t0k3n"))
+
+	client := newClient(t, server.Options())
+	info, err := client.Info(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if info.Code != universum.RespInfoContentOk || !strings.Contains(info.Raw, "keys:0") {
+		t.Fatalf("Expected info content, got %+v", info)
+	}
+}
+
+func TestServer_Close(t *testing.T) {
+	server := universumtest.NewServer(t)
+	client := newClient(t, server.Options())
+
+	if _, err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	server.Close()
+	server.Close()
+
+	if _, err := client.Ping(context.Background()); err == nil {
+		t.Fatal("Expected an error after the server was closed")
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"fmt"
 )
 
+// errNotWriteable describes a value rejected by IsWriteableDatatype
+var errNotWriteable = fmt.Errorf("provided datatype is not supported for write operations, "+
+	"only int|float|bool|string|[]interface{} types are supported: %w", ErrInvalidDatatype)
+
 func convertToStringBool(input map[string]interface{}) (map[string]bool, error) {
 	result := make(map[string]bool)
 
@@ -18,7 +22,9 @@ func convertToStringBool(input map[string]interface{}) (map[string]bool, error)
 	return result, nil
 }
 
-func isWriteableDatatype(value interface{}) bool {
+// IsWriteableDatatype reports whether value can be stored with Set or MSet,
+// i.e. it is a string, bool, integer or float, or a slice of those.
+func IsWriteableDatatype(value interface{}) bool {
 	switch value.(type) {
 	case string, bool,
 		int, int8, int16, int32, int64,
@@ -1,5 +1,8 @@
 package universum
 
+// ProtocolVersion is the protocol version requested in the HELLO handshake
+const ProtocolVersion int64 = 1
+
 func Version() string {
 	return "v0.0.1"
 }